@@ -1,48 +1,101 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"cli_spotify/internal/config"
 	"cli_spotify/internal/daemon"
+	"cli_spotify/internal/library"
 	"cli_spotify/internal/player"
 	"cli_spotify/internal/tui"
 	"cli_spotify/internal/webapi"
 )
 
 func main() {
+	record := flag.String("record", "", "record the daemon event stream and status snapshots to `file`")
+	replay := flag.String("replay", "", "replay a recorded session `file` without a daemon or Spotify account")
+	speed := flag.Float64("replay-speed", 1, "playback speed for --replay (0 delivers all entries at once)")
+	daemonURL := flag.String("daemon-url", "", "control a remote go-librespot daemon at `url` instead of starting one")
+	daemonTokenFile := flag.String("daemon-token-file", "", "read the bearer token for a remote daemon behind the authenticating proxy from `file`")
+	proxyAddr := flag.String("serve-proxy", "", "expose the local daemon to other hosts through an authenticating proxy on `addr`")
+	refresh := flag.Bool("refresh", false, "revalidate every cached Web API response instead of trusting its TTL")
+	flag.Parse()
+
 	cfg := config.Load()
+	if *daemonURL != "" {
+		cfg.DaemonURL = *daemonURL
+	}
+	if *daemonTokenFile != "" {
+		cfg.DaemonTokenFile = *daemonTokenFile
+	}
+	if *proxyAddr != "" {
+		cfg.ProxyAddr = *proxyAddr
+	}
 
-	// Start the go-librespot daemon (handles audio playback).
-	mgr := daemon.NewManager(cfg)
-	if err := mgr.Start(cfg); err != nil {
-		fmt.Fprintf(os.Stderr, "[✗] Failed to start daemon: %v\n", err)
+	switch flag.Arg(0) {
+	case "sync":
+		if err := runSync(cfg, *refresh); err != nil {
+			fmt.Fprintf(os.Stderr, "[✗] Library sync failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case "playlist":
+		if err := runPlaylist(cfg, *refresh, flag.Args()[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "[✗] %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case "search":
+		if err := runSearch(strings.Join(flag.Args()[1:], " ")); err != nil {
+			fmt.Fprintf(os.Stderr, "[✗] Library search failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *replay != "" {
+		if err := runReplay(*replay, *speed); err != nil {
+			fmt.Fprintf(os.Stderr, "[✗] Replay failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Start the go-librespot daemon (handles audio playback), or connect to a
+	// remote one.
+	ep, stop, err := connectDaemon(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[✗] Daemon unavailable: %v\n", err)
 		os.Exit(1)
 	}
-	defer mgr.Stop()
+	defer stop()
 
 	// Authenticate with the Spotify Web API (search and library browsing).
-	web, err := newWebClient(cfg)
+	web, err := newWebClient(cfg, *refresh)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "[✗] Spotify Web API login failed: %v\n", err)
 		os.Exit(1)
 	}
+	defer web.Close()
 	if u, err := web.CurrentUser(); err == nil {
 		fmt.Printf("[✓] Web API authenticated as %s (%s).\n", u.DisplayName, u.Product)
 	}
 
 	// HTTP client for player controls and WebSocket event stream.
-	pc := player.NewClient(cfg.DaemonPort)
+	pc := player.NewClientFor(ep)
 
-	events, err := player.NewEventHandler(cfg.DaemonPort)
+	events, err := player.NewEventHandlerFor(ep)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "[✗] Failed to connect to event stream: %v\n", err)
 		os.Exit(1)
 	}
 	defer events.Close()
-	events.Start()
 
 	// Seed the UI with the current playback status.
 	var status *player.Status
@@ -50,15 +103,175 @@ func main() {
 		status = s
 	}
 
-	if err := tui.Run(tui.New(pc, web, events, status)); err != nil {
+	if *record != "" {
+		rec, err := player.NewRecorder(*record)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[✗] %v\n", err)
+			os.Exit(1)
+		}
+		defer rec.Close()
+		if status != nil {
+			_ = rec.Status(status)
+		}
+		events.Record(rec)
+		rec.PollStatus(pc, 5*time.Second)
+		fmt.Printf("[i] Recording session to %s\n", *record)
+	}
+	events.Start()
+
+	// Keep the local library mirror up to date while the UI runs.
+	var syncer *library.Syncer
+	if mirror, err := openMirror(); err == nil {
+		syncer = library.NewSyncer(web, mirror, syncInterval)
+		if history, err := openHistory(); err == nil {
+			syncer.UseHistory(history)
+		}
+		syncer.Start()
+		defer syncer.Stop()
+	} else {
+		fmt.Fprintf(os.Stderr, "[!] Library mirror unavailable: %v\n", err)
+	}
+
+	if err := tui.Run(tui.New(pc, web, events.Ch, status, syncer)); err != nil {
 		fmt.Fprintf(os.Stderr, "[✗] UI error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// connectDaemon returns the endpoint of the daemon to control and a function
+// that releases it. In remote mode (cfg.DaemonURL) nothing is started locally;
+// otherwise the local daemon is launched, plus the authenticating proxy when
+// cfg.ProxyAddr is set.
+func connectDaemon(cfg *config.Config) (player.Endpoint, func(), error) {
+	if cfg.DaemonURL != "" {
+		token := cfg.DaemonToken
+		if cfg.DaemonTokenFile != "" {
+			b, err := os.ReadFile(cfg.DaemonTokenFile)
+			if err != nil {
+				return player.Endpoint{}, nil, fmt.Errorf("reading daemon token: %w", err)
+			}
+			token = strings.TrimSpace(string(b))
+		}
+		ep, err := player.RemoteEndpoint(cfg.DaemonURL, token, cfg.DaemonCA)
+		if err != nil {
+			return player.Endpoint{}, nil, err
+		}
+		if err := player.NewClientFor(ep).Health(); err != nil {
+			return player.Endpoint{}, nil, fmt.Errorf("remote daemon at %s is not reachable: %w", ep.BaseURL, err)
+		}
+		fmt.Printf("[✓] Connected to remote daemon at %s\n", ep.BaseURL)
+		return ep, func() {}, nil
+	}
+
+	mgr := daemon.NewManager(cfg)
+	if err := mgr.Start(cfg); err != nil {
+		return player.Endpoint{}, nil, err
+	}
+	if cfg.ProxyAddr == "" {
+		return player.LocalEndpoint(cfg.DaemonPort), mgr.Stop, nil
+	}
+
+	proxy, err := daemon.NewProxy(cfg.ProxyAddr, cfg.DaemonPort, cfg.ProxyToken, cfg.ProxyCert, cfg.ProxyKey)
+	if err != nil {
+		mgr.Stop()
+		return player.Endpoint{}, nil, fmt.Errorf("daemon proxy: %w (see SPOTIFY_PROXY_TOKEN, SPOTIFY_PROXY_CERT and SPOTIFY_PROXY_KEY)", err)
+	}
+	errCh := proxy.Start()
+	select {
+	case err := <-errCh:
+		mgr.Stop()
+		return player.Endpoint{}, nil, fmt.Errorf("starting proxy: %w", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	fmt.Printf("[✓] Daemon proxy serving %s\n", proxy.Describe())
+	return player.LocalEndpoint(cfg.DaemonPort), func() {
+		proxy.Stop()
+		mgr.Stop()
+	}, nil
+}
+
+// runReplay feeds a recorded session into the UI. No daemon is started and no
+// Spotify login is needed, so playback controls, search and the library are
+// unavailable.
+func runReplay(path string, speed float64) error {
+	entries, err := player.LoadSession(path)
+	if err != nil {
+		return err
+	}
+	r := player.NewReplayer(entries, speed)
+	defer r.Close()
+	r.Start()
+	return tui.Run(tui.New(nil, nil, r.Ch, r.InitialStatus(), nil))
+}
+
+// syncInterval is how often the library mirror is refreshed in the
+// background while the UI runs.
+const syncInterval = 15 * time.Minute
+
+// openMirror opens the library mirror at ~/.spotify-cli/library.json.
+func openMirror() (*library.Mirror, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return library.Open(filepath.Join(home, ".spotify-cli", "library.json"))
+}
+
+// openHistory opens the playlist history under ~/.spotify-cli/history.
+func openHistory() (*library.History, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return library.OpenHistory(filepath.Join(home, ".spotify-cli", "history")), nil
+}
+
+// runSync implements "spotify sync": one library sync pass with progress on
+// stdout, without starting the daemon.
+func runSync(cfg *config.Config, refresh bool) error {
+	web, err := newWebClient(cfg, refresh)
+	if err != nil {
+		return err
+	}
+	defer web.Close()
+	mirror, err := openMirror()
+	if err != nil {
+		return err
+	}
+	s := library.NewSyncer(web, mirror, syncInterval)
+	history, err := openHistory()
+	if err != nil {
+		return err
+	}
+	s.UseHistory(history)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for p := range s.Progress() {
+			if p.Finished {
+				return
+			}
+			fmt.Printf("[i] %s\n", p)
+		}
+	}()
+	err = s.Sync()
+	select {
+	case <-done:
+	case <-time.After(time.Second): // the final report was dropped
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("[✓] Library synced: %d liked songs, %d albums, %d playlists.\n",
+		len(mirror.Liked()), len(mirror.Albums()), len(mirror.Playlists()))
+	return nil
+}
+
 // newWebClient builds an authenticated Spotify Web API client, running the
 // interactive login on first use and reusing the saved token afterwards.
-func newWebClient(cfg *config.Config) (*webapi.Client, error) {
+// Responses are cached under ~/.spotify-cli/cache; refresh forces
+// revalidation of every cached entry.
+func newWebClient(cfg *config.Config, refresh bool) (*webapi.Client, error) {
 	if cfg.ClientID == "" {
 		return nil, fmt.Errorf("SPOTIFY_CLIENT_ID not set (add it to .env)")
 	}
@@ -66,7 +279,73 @@ func newWebClient(cfg *config.Config) (*webapi.Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	tokenPath := filepath.Join(home, ".spotify-cli", "webapi-token.json")
-	auth := webapi.NewAuthenticator(cfg.ClientID, cfg.RedirectURI, tokenPath)
-	return webapi.NewClient(auth)
+	store, err := tokenStore(cfg, filepath.Join(home, ".spotify-cli"))
+	if err != nil {
+		return nil, err
+	}
+	auth := webapi.NewAuthenticator(cfg.ClientID, cfg.RedirectURI, store)
+	web, err := webapi.NewClient(auth)
+	if err != nil {
+		return nil, err
+	}
+	if err := web.UseCache(webapi.NewCache(filepath.Join(home, ".spotify-cli", "cache", "webapi"), refresh)); err != nil {
+		fmt.Fprintf(os.Stderr, "[!] Response cache disabled: %v\n", err)
+	}
+	if cfg.SearchLimitMax > 0 {
+		web.SetSearchLimitMax(cfg.SearchLimitMax)
+	}
+	return web, nil
+}
+
+// tokenStore builds the Web API token store selected by SPOTIFY_TOKEN_STORE.
+// Switching to the encrypted store moves an existing plain-text token into it.
+func tokenStore(cfg *config.Config, dir string) (webapi.TokenStore, error) {
+	plain := webapi.NewFileStore(filepath.Join(dir, "webapi-token.json"))
+	switch kind := cfg.TokenStore; {
+	case kind == "" || kind == "file":
+		return plain, nil
+	case kind == "encrypted":
+		enc := webapi.NewEncryptedFileStore(filepath.Join(dir, "webapi-token.enc"), cfg.TokenPassphrase)
+		if err := webapi.MigrateToken(plain, enc); err != nil {
+			return nil, fmt.Errorf("encrypting saved token: %w", err)
+		}
+		return enc, nil
+	case kind == "env":
+		return webapi.NewEnvStore("SPOTIFY_WEBAPI_TOKEN"), nil
+	case strings.HasPrefix(kind, "fd:"):
+		fd, err := strconv.ParseUint(strings.TrimPrefix(kind, "fd:"), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("SPOTIFY_TOKEN_STORE: bad file descriptor in %q", kind)
+		}
+		return webapi.NewFDStore(uintptr(fd)), nil
+	default:
+		return nil, fmt.Errorf("SPOTIFY_TOKEN_STORE: unknown store %q (want file, encrypted, env or fd:N)", kind)
+	}
+}
+
+// cliSearchLimit is how many matches "spotify search" prints.
+const cliSearchLimit = 25
+
+// runSearch implements "spotify search <query>": a fuzzy search of the local
+// library mirror. It needs neither the daemon nor the network.
+func runSearch(query string) error {
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("usage: spotify search <query>")
+	}
+	mirror, err := openMirror()
+	if err != nil {
+		return err
+	}
+	if mirror.Synced().IsZero() {
+		return fmt.Errorf("the library has not been synced yet; run \"spotify sync\" first")
+	}
+	results := mirror.Search(query, cliSearchLimit)
+	if len(results) == 0 {
+		fmt.Println("[i] No matches in your library.")
+		return nil
+	}
+	for _, r := range results {
+		fmt.Printf("%-9s %s — %s\n          %s\n", r.Kind, r.Name, r.Detail, r.URI)
+	}
+	return nil
 }
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cli_spotify/internal/config"
+	"cli_spotify/internal/library"
+	"cli_spotify/internal/playlistfile"
+	"cli_spotify/internal/webapi"
+)
+
+const playlistUsage = `usage:
+  spotify playlist export <playlist uri or link> [--format json|csv|m3u|xspf] [-o file]
+  spotify playlist import <file> [--name name] [--public]
+  spotify playlist history <playlist uri or link>
+  spotify playlist diff <playlist uri or link> <from version> <to version>
+  spotify playlist restore <playlist uri or link> <version>`
+
+// runPlaylist implements the "spotify playlist" subcommands.
+func runPlaylist(cfg *config.Config, refresh bool, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", playlistUsage)
+	}
+	switch args[0] {
+	case "export":
+		return runPlaylistExport(cfg, refresh, args[1:])
+	case "import":
+		return runPlaylistImport(cfg, refresh, args[1:])
+	case "history":
+		return runPlaylistHistory(args[1:])
+	case "diff":
+		return runPlaylistDiff(args[1:])
+	case "restore":
+		return runPlaylistRestore(cfg, refresh, args[1:])
+	}
+	return fmt.Errorf("unknown playlist command %q\n%s", args[0], playlistUsage)
+}
+
+// runPlaylistExport writes a playlist to a file, or stdout without -o. The
+// format defaults to the file's extension, then to JSON.
+func runPlaylistExport(cfg *config.Config, refresh bool, args []string) error {
+	fs := flag.NewFlagSet("playlist export", flag.ContinueOnError)
+	format := fs.String("format", "", "output `format`: "+strings.Join(playlistfile.Formats, ", "))
+	out := fs.String("o", "", "write to `file` instead of stdout")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%s", playlistUsage)
+	}
+	uri := playlistArg(pos[0])
+	if *format == "" {
+		*format = playlistfile.FormatFor(*out)
+	}
+	if *format == "" {
+		*format = "json"
+	}
+
+	web, err := newWebClient(cfg, refresh)
+	if err != nil {
+		return err
+	}
+	defer web.Close()
+	p, err := web.Playlist(uri)
+	if err != nil {
+		return err
+	}
+	items, err := web.PlaylistItemsPager(uri).Remaining()
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return playlistfile.Write(os.Stdout, *format, p, items)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := playlistfile.Write(f, *format, p, items); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("[✓] Exported %d items of %s to %s\n", len(items), p.Name, *out)
+	return nil
+}
+
+// runPlaylistImport creates a playlist from a file of Spotify URIs or links
+// ("-" reads stdin). Every reference is looked up first, in batches, so
+// nothing unknown is added; those are reported and skipped.
+func runPlaylistImport(cfg *config.Config, refresh bool, args []string) error {
+	fs := flag.NewFlagSet("playlist import", flag.ContinueOnError)
+	name := fs.String("name", "", "playlist `name` (default: the name in the file, or the file name)")
+	public := fs.Bool("public", false, "make the playlist public")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%s", playlistUsage)
+	}
+
+	var r io.Reader = os.Stdin
+	if pos[0] != "-" {
+		f, err := os.Open(pos[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	fileName, uris, err := playlistfile.Read(r)
+	if err != nil {
+		return err
+	}
+	if len(uris) == 0 {
+		return fmt.Errorf("%s contains no Spotify track or episode URIs", pos[0])
+	}
+	if *name == "" {
+		*name = fileName
+	}
+	if *name == "" {
+		*name = strings.TrimSuffix(filepath.Base(pos[0]), filepath.Ext(pos[0]))
+	}
+
+	web, err := newWebClient(cfg, refresh)
+	if err != nil {
+		return err
+	}
+	defer web.Close()
+	known, err := lookupItems(web, uris)
+	if err != nil {
+		return err
+	}
+	var add []string
+	missing := 0
+	for _, u := range uris {
+		if known[u] {
+			add = append(add, u)
+		} else {
+			missing++
+		}
+	}
+	if len(add) == 0 {
+		return fmt.Errorf("none of the %d items in %s exist on Spotify", len(uris), pos[0])
+	}
+
+	p, err := web.CreatePlaylist(*name, "Imported from "+filepath.Base(pos[0]), *public)
+	if err != nil {
+		return err
+	}
+	if _, err := web.AddPlaylistItems(p.URI, add, -1); err != nil {
+		return fmt.Errorf("created %s but adding its items failed: %w", p.URI, err)
+	}
+	fmt.Printf("[✓] Created %s with %d items (%s)\n", p.Name, len(add), p.URI)
+	if missing > 0 {
+		fmt.Printf("[!] Skipped %d items Spotify does not know.\n", missing)
+	}
+	return nil
+}
+
+// runPlaylistHistory lists the recorded versions of a playlist, each with
+// its changes from the one before. It works offline.
+func runPlaylistHistory(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%s", playlistUsage)
+	}
+	uri := playlistArg(args[0])
+	history, err := openHistory()
+	if err != nil {
+		return err
+	}
+	versions, err := history.Versions(uri)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("no history recorded for %s; run \"spotify sync\" first", uri)
+	}
+	last := versions[len(versions)-1]
+	fmt.Printf("[i] %s: %d versions recorded\n", last.Name, len(versions))
+	for i, v := range versions {
+		line := fmt.Sprintf("  v%-4d %s  %4d items", v.N, v.Taken.Local().Format("2006-01-02 15:04"), len(v.Items))
+		if i > 0 {
+			prev := versions[i-1]
+			d := library.Compare(prev.Items, v.Items)
+			line += fmt.Sprintf("  +%d -%d ~%d", len(d.Added), len(d.Removed), len(d.Moved))
+			if v.Name != prev.Name {
+				line += fmt.Sprintf("  renamed from %q", prev.Name)
+			}
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// runPlaylistDiff shows the tracks added, removed and moved between two
+// recorded versions of a playlist. It works offline.
+func runPlaylistDiff(args []string) error {
+	if len(args) != 3 {
+		return fmt.Errorf("%s", playlistUsage)
+	}
+	uri := playlistArg(args[0])
+	history, err := openHistory()
+	if err != nil {
+		return err
+	}
+	from, err := historyVersion(history, uri, args[1])
+	if err != nil {
+		return err
+	}
+	to, err := historyVersion(history, uri, args[2])
+	if err != nil {
+		return err
+	}
+	tracks, err := history.Tracks(uri)
+	if err != nil {
+		return err
+	}
+	describe := func(uri string) string {
+		t, ok := tracks[uri]
+		switch {
+		case !ok || t.Name == "":
+			return uri
+		case t.Artists == "":
+			return t.Name
+		}
+		return t.Name + " — " + t.Artists
+	}
+
+	fmt.Printf("[i] %s: v%d (%d items) → v%d (%d items)\n", to.Name, from.N, len(from.Items), to.N, len(to.Items))
+	if from.Name != to.Name {
+		fmt.Printf("  renamed from %q\n", from.Name)
+	}
+	d := library.Compare(from.Items, to.Items)
+	if d.Empty() {
+		fmt.Println("[i] Same items in the same order.")
+		return nil
+	}
+	for _, c := range d.Added {
+		fmt.Printf("  + %3d. %s\n", c.To+1, describe(c.URI))
+	}
+	for _, c := range d.Removed {
+		fmt.Printf("  - %3d. %s\n", c.From+1, describe(c.URI))
+	}
+	for _, c := range d.Moved {
+		fmt.Printf("  ~ %3d. %s (was %d)\n", c.To+1, describe(c.URI), c.From+1)
+	}
+	return nil
+}
+
+// runPlaylistRestore sets a playlist's items back to a recorded version. The
+// current items are recorded first, so a restore can itself be undone.
+func runPlaylistRestore(cfg *config.Config, refresh bool, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("%s", playlistUsage)
+	}
+	uri := playlistArg(args[0])
+	history, err := openHistory()
+	if err != nil {
+		return err
+	}
+	target, err := historyVersion(history, uri, args[1])
+	if err != nil {
+		return err
+	}
+
+	web, err := newWebClient(cfg, refresh)
+	if err != nil {
+		return err
+	}
+	defer web.Close()
+	record := func() (*webapi.Playlist, []webapi.Track, error) {
+		p, err := web.Playlist(uri)
+		if err != nil {
+			return nil, nil, err
+		}
+		tracks, err := web.PlaylistTracksPager(uri).Remaining()
+		if err != nil {
+			return nil, nil, err
+		}
+		_, err = history.Record(*p, tracks)
+		return p, tracks, err
+	}
+	p, tracks, err := record()
+	if err != nil {
+		return err
+	}
+	// The API lists local files but cannot add them, so replacing the items
+	// would delete them.
+	if hidden := p.Tracks.Total - len(tracks); hidden > 0 {
+		return fmt.Errorf("%s has %d local files or other items the Web API cannot add back; restoring would delete them", p.Name, hidden)
+	}
+	current := make([]string, len(tracks))
+	for i, t := range tracks {
+		current[i] = t.LibraryURI()
+	}
+	if slices.Equal(current, target.Items) {
+		fmt.Printf("[i] %s already matches v%d.\n", p.Name, target.N)
+		return nil
+	}
+	before, err := history.Version(uri, 0)
+	if err != nil {
+		return err
+	}
+
+	undo := fmt.Sprintf("spotify playlist restore %s %d", uri, before.N)
+	if _, err := web.ReplacePlaylistItems(uri, target.Items); err != nil {
+		// Long playlists are replaced in batches, so the first ones may have
+		// been applied.
+		fmt.Printf("[!] %s may be partly restored. To undo: %s\n", p.Name, undo)
+		return err
+	}
+	d := library.Compare(current, target.Items)
+	fmt.Printf("[✓] Restored %s to v%d: %d added, %d removed, %d moved.\n",
+		p.Name, target.N, len(d.Added), len(d.Removed), len(d.Moved))
+	if _, _, err := record(); err != nil {
+		fmt.Printf("[!] Could not record the restored version: %v\n", err)
+	}
+	fmt.Printf("[i] To undo: %s\n", undo)
+	return nil
+}
+
+// historyVersion resolves a version argument: a number, optionally prefixed
+// with "v", or "latest".
+func historyVersion(h *library.History, uri, arg string) (*library.Version, error) {
+	if arg == "latest" {
+		return h.Version(uri, 0)
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(arg, "v"))
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("bad version %q (want a number such as 3, or latest)", arg)
+	}
+	return h.Version(uri, n)
+}
+
+// playlistArg turns a playlist URI, open.spotify.com link or bare ID into a
+// URI.
+func playlistArg(s string) string {
+	uri, ok := playlistfile.ParseURI(s)
+	switch {
+	case ok && strings.HasPrefix(uri, "spotify:playlist:"):
+		return uri
+	case strings.HasPrefix(s, "spotify:playlist:"):
+		return s
+	}
+	return "spotify:playlist:" + s // a bare ID
+}
+
+// lookupItems reports which of the track and episode URIs exist, using the
+// batch lookups. Relinked tracks count under the URI they were requested by.
+func lookupItems(web *webapi.Client, uris []string) (map[string]bool, error) {
+	var tracks, episodes []string
+	seen := make(map[string]bool)
+	for _, u := range uris {
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+		if strings.HasPrefix(u, "spotify:episode:") {
+			episodes = append(episodes, u)
+		} else {
+			tracks = append(tracks, u)
+		}
+	}
+
+	known := make(map[string]bool, len(seen))
+	ts, err := web.Tracks(tracks)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range ts {
+		known[t.LibraryURI()] = true
+	}
+	es, err := web.Episodes(episodes)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range es {
+		known[e.URI] = true
+	}
+	return known, nil
+}
+
+// parseInterspersed parses flags that may come before, between or after
+// positional arguments, returning the positional ones.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return pos, nil
+		}
+		pos = append(pos, args[0])
+		args = args[1:]
+	}
+}
@@ -20,11 +20,42 @@ type Config struct {
 	// macOS) — build go-librespot yourself and point this at it.
 	LibrespotPath string
 
+	// DaemonURL points at a go-librespot daemon on another host (e.g.
+	// http://pi.local:3678). When set, no local daemon is started.
+	// DaemonToken is the bearer token of the authenticating proxy in front of
+	// it, or DaemonTokenFile a file holding it (kept out of the command line,
+	// where other users could read it), and DaemonCA an optional PEM file
+	// trusted for a self-signed proxy certificate.
+	DaemonURL       string
+	DaemonToken     string
+	DaemonTokenFile string
+	DaemonCA        string
+
+	// ProxyAddr, when set, serves the local daemon's API to other hosts through
+	// an authenticating reverse proxy on that address (e.g. ":3679"). Requests
+	// must carry ProxyToken as a bearer token; ProxyCert/ProxyKey enable TLS.
+	ProxyAddr  string
+	ProxyToken string
+	ProxyCert  string
+	ProxyKey   string
+
 	// Spotify Web API (used for search and library/playlist browsing). Auth uses
 	// the Authorization Code flow with PKCE, so only the Client ID is required —
 	// the Client Secret is not used.
 	ClientID    string
 	RedirectURI string
+
+	// TokenStore selects where the Web API token is kept: "file" (plain JSON,
+	// the default), "encrypted" (encrypted with TokenPassphrase, or a machine
+	// key when that is empty), "env" (read-only, from SPOTIFY_WEBAPI_TOKEN) or
+	// "fd:N" (read-only, from inherited file descriptor N).
+	TokenStore      string
+	TokenPassphrase string
+
+	// SearchLimitMax caps per-type search result counts to the app's quota
+	// (SPOTIFY_SEARCH_LIMIT_MAX). Zero keeps the development-mode cap; apps
+	// with extended quota can raise it to 50.
+	SearchLimitMax int
 }
 
 // Load reads configuration from the .env file or system environment variables.
@@ -50,11 +81,31 @@ func Load() *Config {
 		redirectURI = "http://127.0.0.1:8080/callback"
 	}
 
+	searchMax := 0
+	if v := os.Getenv("SPOTIFY_SEARCH_LIMIT_MAX"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			searchMax = n
+		}
+	}
+
 	return &Config{
-		DeviceName:    deviceName,
-		DaemonPort:    port,
-		LibrespotPath: os.Getenv("SPOTIFY_LIBRESPOT_PATH"),
-		ClientID:      os.Getenv("SPOTIFY_CLIENT_ID"),
-		RedirectURI:   redirectURI,
+		DeviceName:      deviceName,
+		DaemonPort:      port,
+		LibrespotPath:   os.Getenv("SPOTIFY_LIBRESPOT_PATH"),
+		DaemonURL:       os.Getenv("SPOTIFY_DAEMON_URL"),
+		DaemonToken:     os.Getenv("SPOTIFY_DAEMON_TOKEN"),
+		DaemonTokenFile: os.Getenv("SPOTIFY_DAEMON_TOKEN_FILE"),
+		DaemonCA:        os.Getenv("SPOTIFY_DAEMON_CA"),
+		ProxyAddr:       os.Getenv("SPOTIFY_PROXY_ADDR"),
+		ProxyToken:      os.Getenv("SPOTIFY_PROXY_TOKEN"),
+		ProxyCert:       os.Getenv("SPOTIFY_PROXY_CERT"),
+		ProxyKey:        os.Getenv("SPOTIFY_PROXY_KEY"),
+		ClientID:        os.Getenv("SPOTIFY_CLIENT_ID"),
+		RedirectURI:     redirectURI,
+
+		TokenStore:      os.Getenv("SPOTIFY_TOKEN_STORE"),
+		TokenPassphrase: os.Getenv("SPOTIFY_TOKEN_PASSPHRASE"),
+
+		SearchLimitMax: searchMax,
 	}
 }
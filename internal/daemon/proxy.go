@@ -0,0 +1,123 @@
+package daemon
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Proxy exposes the local go-librespot API to other hosts. The daemon itself
+// only listens on localhost and has no authentication, so remote clients go
+// through this reverse proxy instead, which checks a bearer token and can
+// terminate TLS. WebSocket upgrades (/events) are proxied as well.
+type Proxy struct {
+	addr     string
+	token    string
+	certFile string
+	keyFile  string
+	srv      *http.Server
+}
+
+// NewProxy creates a proxy listening on addr (e.g. ":3679") and forwarding to
+// the daemon on localhost:port. Empty certFile and keyFile serve plain HTTP.
+// An empty token disables authentication, which is only allowed when addr is
+// a loopback address: anything else would hand playback control to the whole
+// network.
+func NewProxy(addr string, port int, token, certFile, keyFile string) (*Proxy, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("TLS needs both a certificate and a key file")
+	}
+	if token == "" && !isLoopback(addr) {
+		return nil, fmt.Errorf("refusing to serve %s without a token; set one or listen on a loopback address", addr)
+	}
+
+	target := &url.URL{Scheme: "http", Host: fmt.Sprintf("localhost:%d", port)}
+	rp := httputil.NewSingleHostReverseProxy(target)
+
+	p := &Proxy{
+		addr:     addr,
+		token:    token,
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	p.srv = &http.Server{
+		Addr:              addr,
+		Handler:           p.authenticate(rp),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return p, nil
+}
+
+// isLoopback reports whether a listen address only accepts local connections.
+// An empty host (":3679") listens on every interface.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Start begins serving in the background. Listen errors (e.g. the port is
+// taken) are reported on the returned channel.
+func (p *Proxy) Start() <-chan error {
+	errCh := make(chan error, 1)
+	go func() {
+		var err error
+		if p.certFile != "" {
+			err = p.srv.ListenAndServeTLS(p.certFile, p.keyFile)
+		} else {
+			err = p.srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+	return errCh
+}
+
+// Stop shuts the proxy down.
+func (p *Proxy) Stop() {
+	_ = p.srv.Close()
+}
+
+// Describe returns a one-line summary of how the proxy is exposed.
+func (p *Proxy) Describe() string {
+	scheme := "http"
+	if p.certFile != "" {
+		scheme = "https"
+	}
+	auth := "token auth"
+	if p.token == "" {
+		auth = "no authentication, local only"
+	}
+	return fmt.Sprintf("%s on %s (%s)", scheme, p.addr, auth)
+}
+
+// authenticate rejects requests without the expected bearer token. The
+// header must use the Bearer scheme; a bare token is refused.
+func (p *Proxy) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p.token != "" {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(p.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="go-librespot"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		// The daemon has no use for the credential.
+		r.Header.Del("Authorization")
+		next.ServeHTTP(w, r)
+	})
+}
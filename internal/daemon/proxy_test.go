@@ -0,0 +1,70 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewProxyRefusesUnsafeSetups(t *testing.T) {
+	tests := []struct {
+		name        string
+		addr, token string
+		cert, key   string
+		wantErr     bool
+	}{
+		{name: "token on all interfaces", addr: ":3679", token: "secret"},
+		{name: "no token on all interfaces", addr: ":3679", wantErr: true},
+		{name: "no token on a LAN address", addr: "192.168.1.20:3679", wantErr: true},
+		{name: "no token on loopback", addr: "127.0.0.1:3679"},
+		{name: "no token on localhost", addr: "localhost:3679"},
+		{name: "no token on IPv6 loopback", addr: "[::1]:3679"},
+		{name: "TLS", addr: ":3679", token: "secret", cert: "c.pem", key: "k.pem"},
+		{name: "cert without key", addr: ":3679", token: "secret", cert: "c.pem", wantErr: true},
+		{name: "key without cert", addr: ":3679", token: "secret", key: "k.pem", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewProxy(tt.addr, 3678, tt.token, tt.cert, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewProxy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && p == nil {
+				t.Fatal("NewProxy() returned neither a proxy nor an error")
+			}
+		})
+	}
+}
+
+func TestAuthenticateRequiresBearerScheme(t *testing.T) {
+	p, err := NewProxy(":3679", 3678, "secret", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := p.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Error("the credential was passed on to the daemon")
+		}
+	}))
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{"Bearer secret", http.StatusOK},
+		{"secret", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"Basic secret", http.StatusUnauthorized},
+		{"", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/status", nil)
+		if tt.header != "" {
+			r.Header.Set("Authorization", tt.header)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("Authorization %q: status %d, want %d", tt.header, w.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package library
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"cli_spotify/internal/webapi"
+)
+
+// maxVersions is how many versions of each playlist History keeps; older
+// ones are dropped first.
+const maxVersions = 200
+
+// History is a local, versioned store of playlist contents: one file per
+// playlist holding its successive versions, recorded whenever a sync sees a
+// new snapshot_id. It lets changes made elsewhere (e.g. by collaborators) be
+// reviewed and undone.
+type History struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// Version is one recorded state of a playlist.
+type Version struct {
+	N        int       `json:"n"` // 1 for the oldest recorded version
+	Snapshot string    `json:"snapshot"`
+	Taken    time.Time `json:"taken"`
+	Name     string    `json:"name"`
+	Items    []string  `json:"items"` // track and episode URIs, in order
+}
+
+// TrackInfo is what History remembers about an item for display.
+type TrackInfo struct {
+	Name    string `json:"name"`
+	Artists string `json:"artists"`
+}
+
+// playlistHistory is the file of one playlist.
+type playlistHistory struct {
+	URI      string               `json:"uri"`
+	Versions []Version            `json:"versions"`
+	Tracks   map[string]TrackInfo `json:"tracks"` // by URI, across versions
+}
+
+// OpenHistory returns the History stored under dir (e.g.
+// ~/.spotify-cli/history). The directory is created on first write.
+func OpenHistory(dir string) *History {
+	return &History{dir: dir}
+}
+
+// Record stores the current contents of p as a new version, unless they are
+// the same as the latest version's. It reports whether a version was added.
+func (h *History) Record(p webapi.Playlist, tracks []webapi.Track) (bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ph, err := h.load(p.URI)
+	if err != nil {
+		return false, err
+	}
+	items := make([]string, len(tracks))
+	for i, t := range tracks {
+		items[i] = t.LibraryURI()
+		if t.Name != "" {
+			ph.Tracks[items[i]] = TrackInfo{Name: t.Name, Artists: t.ArtistNames()}
+		}
+	}
+	if n := len(ph.Versions); n > 0 {
+		last := ph.Versions[n-1]
+		if last.Snapshot == p.SnapshotID || (last.Name == p.Name && slices.Equal(last.Items, items)) {
+			return false, nil
+		}
+	}
+	next := 1
+	if n := len(ph.Versions); n > 0 {
+		next = ph.Versions[n-1].N + 1
+	}
+	ph.Versions = append(ph.Versions, Version{
+		N:        next,
+		Snapshot: p.SnapshotID,
+		Taken:    time.Now().UTC(),
+		Name:     p.Name,
+		Items:    items,
+	})
+	if len(ph.Versions) > maxVersions {
+		ph.Versions = ph.Versions[len(ph.Versions)-maxVersions:]
+	}
+	return true, h.save(ph)
+}
+
+// Versions returns the recorded versions of a playlist, oldest first.
+func (h *History) Versions(uri string) ([]Version, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ph, err := h.load(uri)
+	if err != nil {
+		return nil, err
+	}
+	return ph.Versions, nil
+}
+
+// Version returns version n of a playlist; n <= 0 selects the latest.
+func (h *History) Version(uri string, n int) (*Version, error) {
+	versions, err := h.Versions(uri)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no history recorded for %s; run \"spotify sync\" first", uri)
+	}
+	if n <= 0 {
+		return &versions[len(versions)-1], nil
+	}
+	for i := range versions {
+		if versions[i].N == n {
+			return &versions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no version %d (versions %d to %d are kept)", uri, n, versions[0].N, versions[len(versions)-1].N)
+}
+
+// Tracks returns what History knows about the items that have been in a
+// playlist, by URI.
+func (h *History) Tracks(uri string) (map[string]TrackInfo, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ph, err := h.load(uri)
+	if err != nil {
+		return nil, err
+	}
+	return ph.Tracks, nil
+}
+
+func (h *History) path(uri string) string {
+	return filepath.Join(h.dir, strings.ReplaceAll(uri, ":", "_")+".json")
+}
+
+func (h *History) load(uri string) (*playlistHistory, error) {
+	ph := &playlistHistory{URI: uri, Tracks: make(map[string]TrackInfo)}
+	data, err := os.ReadFile(h.path(uri))
+	if errors.Is(err, os.ErrNotExist) {
+		return ph, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, ph); err != nil {
+		return nil, fmt.Errorf("reading history of %s: %w", uri, err)
+	}
+	if ph.Tracks == nil {
+		ph.Tracks = make(map[string]TrackInfo)
+	}
+	return ph, nil
+}
+
+// save writes a playlist's history atomically (temporary file and rename).
+func (h *History) save(ph *playlistHistory) error {
+	data, err := json.Marshal(ph)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(h.dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(h.dir, ".history-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), h.path(ph.URI))
+}
+
+// Change is one difference between two versions. From and To are positions
+// (0-based) in the older and newer version; -1 where the item is absent.
+type Change struct {
+	URI      string
+	From, To int
+}
+
+// Diff is what changed between two versions of a playlist.
+type Diff struct {
+	Added   []Change
+	Removed []Change
+	Moved   []Change
+}
+
+// Empty reports whether the versions have the same items in the same order.
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Moved) == 0
+}
+
+// Compare computes the changes from one list of items to another. Items kept
+// in order (a longest common subsequence) are unchanged; of the rest, those
+// present in both lists moved, and the others were added or removed.
+// Duplicates are matched up one for one.
+func Compare(from, to []string) Diff {
+	keptFrom, keptTo := lcs(from, to)
+
+	// Unmatched items of the old list, by URI, in order.
+	gone := make(map[string][]int)
+	for i, u := range from {
+		if !keptFrom[i] {
+			gone[u] = append(gone[u], i)
+		}
+	}
+	var d Diff
+	for j, u := range to {
+		if keptTo[j] {
+			continue
+		}
+		if idx := gone[u]; len(idx) > 0 {
+			d.Moved = append(d.Moved, Change{URI: u, From: idx[0], To: j})
+			gone[u] = idx[1:]
+			continue
+		}
+		d.Added = append(d.Added, Change{URI: u, From: -1, To: j})
+	}
+	for i, u := range from {
+		if !keptFrom[i] && slices.Contains(gone[u], i) {
+			d.Removed = append(d.Removed, Change{URI: u, From: i, To: -1})
+		}
+	}
+	return d
+}
+
+// lcs marks the items of a and b that belong to a longest common
+// subsequence of the two. The common prefix and suffix are matched directly,
+// so the quadratic-time part only covers the region that changed, and that
+// part runs in linear space (Hirschberg's algorithm): a 10,000-item playlist
+// needs a few rows of counters rather than a 10,000 × 10,000 table.
+func lcs(a, b []string) (inA, inB []bool) {
+	inA, inB = make([]bool, len(a)), make([]bool, len(b))
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		inA[pre], inB[pre] = true, true
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		inA[len(a)-1-suf], inB[len(b)-1-suf] = true, true
+		suf++
+	}
+
+	// Compare small integers rather than URIs in the inner loops.
+	ids := make(map[string]int)
+	intern := func(items []string) []int {
+		out := make([]int, len(items))
+		for i, u := range items {
+			id, ok := ids[u]
+			if !ok {
+				id = len(ids)
+				ids[u] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+	midA, midB := intern(a[pre:len(a)-suf]), intern(b[pre:len(b)-suf])
+	hirschberg(midA, midB, inA[pre:], inB[pre:])
+	return inA, inB
+}
+
+// hirschberg marks in inA and inB the items of a longest common subsequence
+// of a and b. It splits a in half, finds where the halves' subsequences meet
+// in b from one forward and one backward pass, and recurses on both sides.
+func hirschberg(a, b []int, inA, inB []bool) {
+	switch {
+	case len(a) == 0 || len(b) == 0:
+		return
+	case len(a) == 1:
+		if j := slices.Index(b, a[0]); j >= 0 {
+			inA[0], inB[j] = true, true
+		}
+		return
+	}
+	mid := len(a) / 2
+	head := lcsPrefixes(a[:mid], b) // head[j]: LCS length of a[:mid] and b[:j]
+	tail := lcsSuffixes(a[mid:], b) // tail[j]: LCS length of a[mid:] and b[j:]
+	split := 0
+	for j := range head {
+		if head[j]+tail[j] > head[split]+tail[split] {
+			split = j
+		}
+	}
+	hirschberg(a[:mid], b[:split], inA[:mid], inB[:split])
+	hirschberg(a[mid:], b[split:], inA[mid:], inB[split:])
+}
+
+// lcsPrefixes returns, for every j, the LCS length of a and b[:j].
+func lcsPrefixes(a, b []int) []int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for _, x := range a {
+		for j := 1; j <= len(b); j++ {
+			if x == b[j-1] {
+				cur[j] = prev[j-1] + 1
+			} else {
+				cur[j] = max(prev[j], cur[j-1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+// lcsSuffixes returns, for every j, the LCS length of a and b[j:].
+func lcsSuffixes(a, b []int) []int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				cur[j] = prev[j+1] + 1
+			} else {
+				cur[j] = max(prev[j], cur[j+1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
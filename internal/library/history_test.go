@@ -0,0 +1,96 @@
+package library
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name                  string
+		from, to              []string
+		added, removed, moved int
+	}{
+		{name: "same", from: []string{"a", "b"}, to: []string{"a", "b"}},
+		{name: "added", from: []string{"a", "b"}, to: []string{"a", "x", "b"}, added: 1},
+		{name: "removed", from: []string{"a", "b", "c"}, to: []string{"a", "c"}, removed: 1},
+		{name: "moved", from: []string{"a", "b", "c", "d"}, to: []string{"d", "a", "b", "c"}, moved: 1},
+		{name: "duplicate removed", from: []string{"a", "b", "a"}, to: []string{"a", "b"}, removed: 1},
+		{name: "emptied", from: []string{"a", "b"}, removed: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Compare(tt.from, tt.to)
+			if len(d.Added) != tt.added || len(d.Removed) != tt.removed || len(d.Moved) != tt.moved {
+				t.Errorf("Compare() = %d added, %d removed, %d moved; want %d, %d, %d",
+					len(d.Added), len(d.Removed), len(d.Moved), tt.added, tt.removed, tt.moved)
+			}
+		})
+	}
+}
+
+// lcsLength is the textbook quadratic-space LCS length.
+func lcsLength(a, b []string) int {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+	return table[0][0]
+}
+
+func kept(items []string, in []bool) []string {
+	var out []string
+	for i, u := range items {
+		if in[i] {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+func TestLCSIsLongestCommonSubsequence(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	randomList := func() []string {
+		out := make([]string, r.IntN(30))
+		for i := range out {
+			out[i] = strconv.Itoa(r.IntN(6))
+		}
+		return out
+	}
+	for range 500 {
+		a, b := randomList(), randomList()
+		inA, inB := lcs(a, b)
+		ka, kb := kept(a, inA), kept(b, inB)
+		if !slices.Equal(ka, kb) {
+			t.Fatalf("lcs(%q, %q) kept %q and %q, not a common subsequence", a, b, ka, kb)
+		}
+		if want := lcsLength(a, b); len(ka) != want {
+			t.Fatalf("lcs(%q, %q) kept %d items, want %d", a, b, len(ka), want)
+		}
+	}
+}
+
+func TestCompareLargePlaylist(t *testing.T) {
+	from := make([]string, 10000)
+	for i := range from {
+		from[i] = "spotify:track:" + strconv.Itoa(i)
+	}
+	to := slices.Clone(from)
+	rand.New(rand.NewPCG(3, 4)).Shuffle(len(to), func(i, j int) { to[i], to[j] = to[j], to[i] })
+
+	d := Compare(from, to)
+	if len(d.Added) != 0 || len(d.Removed) != 0 || len(d.Moved) == 0 {
+		t.Errorf("shuffle: %d added, %d removed, %d moved; want only moves", len(d.Added), len(d.Removed), len(d.Moved))
+	}
+}
@@ -0,0 +1,321 @@
+package library
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"cli_spotify/internal/webapi"
+)
+
+// Kind is the type of a search result.
+type Kind string
+
+const (
+	KindTrack    Kind = "track"
+	KindAlbum    Kind = "album"
+	KindArtist   Kind = "artist"
+	KindPlaylist Kind = "playlist"
+)
+
+// kindOrder breaks ties between equally good results of different kinds.
+var kindOrder = map[Kind]int{KindTrack: 0, KindAlbum: 1, KindArtist: 2, KindPlaylist: 3}
+
+// Result is one match of a library search. Exactly one of Track, Album,
+// Artist and Playlist is set, according to Kind.
+type Result struct {
+	Kind   Kind
+	Name   string
+	Detail string // artists, owner or track count, for display
+	URI    string
+	Score  float64
+
+	Track    *webapi.Track
+	Album    *webapi.Album
+	Artist   *webapi.Artist
+	Playlist *webapi.Playlist
+}
+
+// index is the search index over one generation of the mirror.
+type index struct {
+	gen  int
+	docs []doc
+}
+
+// doc is an indexed result with its normalized words.
+type doc struct {
+	res   Result
+	full  string   // normalized name
+	name  []string // words of the name
+	other []string // words of artists, album or owner
+}
+
+// Search finds tracks, albums, artists and playlists in the mirror matching
+// query, best first. Matching is per word and tolerates typos and partially
+// typed words; every query word must match something. It works offline.
+func (m *Mirror) Search(query string, limit int) []Result {
+	q := words(query)
+	if len(q) == 0 {
+		return nil
+	}
+	ix := m.searchIndex()
+	phrase := strings.Join(q, " ")
+
+	var out []Result
+	for i := range ix.docs {
+		d := &ix.docs[i]
+		score := d.match(q)
+		if score == 0 {
+			continue
+		}
+		switch {
+		case d.full == phrase:
+			score += 1
+		case strings.HasPrefix(d.full, phrase):
+			score += 0.5
+		}
+		r := d.res
+		r.Score = score
+		out = append(out, r)
+	}
+	slices.SortStableFunc(out, func(a, b Result) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(kindOrder[a.Kind], kindOrder[b.Kind]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// searchIndex returns the index for the current mirror contents, building it
+// if the mirror has changed since the last search.
+func (m *Mirror) searchIndex() *index {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	m.indexMu.Lock()
+	defer m.indexMu.Unlock()
+	if m.index == nil || m.index.gen != m.gen {
+		m.index = buildIndex(&m.data)
+		m.index.gen = m.gen
+	}
+	return m.index
+}
+
+// buildIndex indexes the tracks of Liked Songs and every mirrored playlist,
+// the saved albums, the artists of all of those, and the playlists.
+func buildIndex(d *mirrorData) *index {
+	ix := &index{}
+	add := func(r Result, other ...string) {
+		w := words(r.Name)
+		ix.docs = append(ix.docs, doc{
+			res:   r,
+			full:  strings.Join(w, " "),
+			name:  w,
+			other: words(strings.Join(other, " ")),
+		})
+	}
+
+	seen := make(map[string]bool)
+	artistTracks := make(map[string]int)
+	var artists []webapi.Artist
+	addArtists := func(as []webapi.Artist, track bool) {
+		for _, a := range as {
+			if a.URI == "" {
+				continue
+			}
+			if track {
+				artistTracks[a.URI]++
+			}
+			if !seen[a.URI] {
+				seen[a.URI] = true
+				artists = append(artists, a)
+			}
+		}
+	}
+
+	addTracks := func(tracks []webapi.Track) {
+		for i := range tracks {
+			t := &tracks[i]
+			if t.URI == "" || seen[t.URI] {
+				continue
+			}
+			seen[t.URI] = true
+			detail := t.ArtistNames()
+			if t.Album.Name != "" {
+				detail += " · " + t.Album.Name
+			}
+			add(Result{Kind: KindTrack, Name: t.Name, Detail: detail, URI: t.URI, Track: t},
+				t.ArtistNames(), t.Album.Name)
+			addArtists(t.Artists, true)
+		}
+	}
+	addTracks(d.Liked)
+	for _, p := range d.Playlists {
+		addTracks(d.Items[p.URI].Tracks)
+	}
+
+	for i := range d.Albums {
+		a := &d.Albums[i]
+		add(Result{Kind: KindAlbum, Name: a.Name, Detail: a.ArtistNames(), URI: a.URI, Album: a}, a.ArtistNames())
+		addArtists(a.Artists, false)
+	}
+	for i := range artists {
+		a := &artists[i]
+		detail := "in saved albums"
+		if n := artistTracks[a.URI]; n == 1 {
+			detail = "1 track in library"
+		} else if n > 1 {
+			detail = strconv.Itoa(n) + " tracks in library"
+		}
+		add(Result{Kind: KindArtist, Name: a.Name, Detail: detail, URI: a.URI, Artist: a})
+	}
+	for i := range d.Playlists {
+		p := &d.Playlists[i]
+		add(Result{Kind: KindPlaylist, Name: p.Name, Detail: p.Owner.DisplayName, URI: p.URI, Playlist: p}, p.Owner.DisplayName)
+	}
+	return ix
+}
+
+// otherWeight discounts matches outside the name, so "abbey" ranks the album
+// Abbey Road above each of its tracks.
+const otherWeight = 0.8
+
+// match scores d against the query words: the mean of each word's best
+// match, or 0 if any word matches nothing.
+func (d *doc) match(q []string) float64 {
+	var total float64
+	for _, w := range q {
+		best := max(bestMatch(w, d.name), otherWeight*bestMatch(w, d.other))
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return total / float64(len(q))
+}
+
+func bestMatch(q string, ws []string) float64 {
+	var best float64
+	for _, w := range ws {
+		best = max(best, wordScore(q, w))
+		if best == 1 {
+			break
+		}
+	}
+	return best
+}
+
+// wordScore rates how well query word q matches word w, from 1 (equal) down
+// to 0 (no match): prefixes (a word still being typed) score just below
+// equality, then words within a few typos, then substrings.
+func wordScore(q, w string) float64 {
+	if q == w {
+		return 1
+	}
+	if strings.HasPrefix(w, q) {
+		return 0.9
+	}
+	qr, wr := []rune(q), []rune(w)
+	if edits := maxEdits(len(qr)); edits > 0 {
+		if d := distance(qr, wr); d <= edits {
+			return 0.8 - 0.15*float64(d)
+		}
+		if len(wr) > len(qr) {
+			// A typo in a word still being typed.
+			if d := distance(qr, wr[:len(qr)]); d <= edits {
+				return 0.75 - 0.15*float64(d)
+			}
+		}
+	}
+	if len(qr) >= 3 && strings.Contains(w, q) {
+		return 0.4
+	}
+	return 0
+}
+
+// maxEdits is the number of typos tolerated in a word of n letters.
+func maxEdits(n int) int {
+	switch {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	}
+	return 2
+}
+
+// distance is the optimal string alignment distance between a and b: the
+// edits (insertions, deletions, substitutions and adjacent transpositions)
+// needed to turn one into the other.
+func distance(a, b []rune) int {
+	if d := len(a) - len(b); d > 2 || d < -2 {
+		return max(len(a), len(b)) // more than any maxEdits; skip the work
+	}
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// words lowercases s, folds common accented letters to their base letter and
+// splits it into words of letters and digits. Apostrophes are dropped rather
+// than splitting, so "don't" matches "dont".
+func words(s string) []string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r == '\'' || r == '’':
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if f, ok := fold[r]; ok {
+				b.WriteString(f)
+			} else {
+				b.WriteRune(r)
+			}
+		default:
+			b.WriteByte(' ')
+		}
+	}
+	return strings.Fields(b.String())
+}
+
+// fold maps accented Latin letters to their unaccented form.
+var fold = func() map[rune]string {
+	m := make(map[rune]string)
+	for base, accented := range map[string]string{
+		"a": "àáâãäåāăą", "c": "çćčĉ", "d": "ďđ", "e": "èéêëēĕėęě",
+		"g": "ğĝģ", "i": "ìíîïīĭįı", "l": "łľĺļ", "n": "ñńňņ",
+		"o": "òóôõöøōŏő", "r": "řŕ", "s": "šśşș", "t": "ťţț",
+		"u": "ùúûüūŭůűų", "y": "ýÿ", "z": "žźż",
+		"ae": "æ", "oe": "œ", "ss": "ß",
+	} {
+		for _, r := range accented {
+			m[r] = base
+		}
+	}
+	return m
+}()
@@ -0,0 +1,178 @@
+package library
+
+import (
+	"slices"
+	"testing"
+
+	"cli_spotify/internal/webapi"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Don't Stop Me Now", []string{"dont", "stop", "me", "now"}},
+		{"Don’t Stop", []string{"dont", "stop"}},
+		{"Café del Mar", []string{"cafe", "del", "mar"}},
+		{"Ærøskøbing Straße", []string{"aeroskobing", "strasse"}},
+		{"Björk — Jóga (Live, 1997)", []string{"bjork", "joga", "live", "1997"}},
+		{"AC/DC", []string{"ac", "dc"}},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		if got := words(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("words(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"beatles", "beatles", 0},
+		{"beatls", "beatles", 1},   // deletion
+		{"beatlees", "beatles", 1}, // insertion
+		{"beatlas", "beatles", 1},  // substitution
+		{"beatels", "beatles", 1},  // adjacent transposition
+		{"ebatels", "beatles", 2},  // two transpositions
+		{"ca", "abc", 3},           // OSA edits no substring twice
+		{"kitten", "sitting", 3},
+		{"abcdefgh", "abcde", 8}, // too far apart in length to be worth computing
+	}
+	for _, tt := range tests {
+		if got := distance([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := distance([]rune(tt.b), []rune(tt.a)); got != tt.want {
+			t.Errorf("distance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestWordScore(t *testing.T) {
+	tests := []struct {
+		q, w string
+		want float64
+	}{
+		{"beatles", "beatles", 1},
+		{"beat", "beatles", 0.9},     // still typing
+		{"beatels", "beatles", 0.65}, // one typo
+		{"baetlse", "beatles", 0.5},  // two typos in a long word
+		{"beatlse", "beatles", 0.65}, // transposition at the end
+		{"baet", "beatles", 0.6},     // a typo in a word still being typed
+		{"cat", "car", 0},            // no typos in short words
+		{"road", "crossroads", 0.4},  // substring
+		{"ro", "crossroads", 0},      // too short for a substring
+		{"zeppelin", "beatles", 0},   // nothing alike
+		{"beatlesque", "beatles", 0}, // three letters too many
+	}
+	for _, tt := range tests {
+		if got := wordScore(tt.q, tt.w); !near(got, tt.want) {
+			t.Errorf("wordScore(%q, %q) = %v, want %v", tt.q, tt.w, got, tt.want)
+		}
+	}
+}
+
+func near(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
+
+// testMirror returns a mirror holding a small library.
+func testMirror(t *testing.T) *Mirror {
+	t.Helper()
+	beatles := webapi.Artist{URI: "spotify:artist:beatles", Name: "The Beatles"}
+	queen := webapi.Artist{URI: "spotify:artist:queen", Name: "Queen"}
+	abbey := webapi.Album{URI: "spotify:album:abbey", Name: "Abbey Road", Artists: []webapi.Artist{beatles}}
+	track := func(id, name string, artist webapi.Artist, album webapi.Album) webapi.Track {
+		return webapi.Track{URI: "spotify:track:" + id, Name: name, Artists: []webapi.Artist{artist}, Album: album}
+	}
+	trip := webapi.Playlist{URI: "spotify:playlist:trip", Name: "Road Trip", SnapshotID: "s1", Owner: webapi.Owner{DisplayName: "Test User"}}
+
+	m := &Mirror{}
+	m.update(func(d *mirrorData) {
+		d.Liked = []webapi.Track{
+			track("come", "Come Together", beatles, abbey),
+			track("something", "Something", beatles, abbey),
+			track("dont", "Don't Stop Me Now", queen, webapi.Album{Name: "Jazz"}),
+		}
+		d.Albums = []webapi.Album{abbey}
+		d.Playlists = []webapi.Playlist{trip}
+		d.Items = map[string]playlistItems{trip.URI: {Snapshot: "s1", Tracks: []webapi.Track{
+			track("cafe", "Café del Mar", webapi.Artist{URI: "spotify:artist:energy", Name: "Energy 52"}, webapi.Album{Name: "Café del Mar"}),
+			track("come", "Come Together", beatles, abbey), // also liked: listed once
+		}}}
+	})
+	return m
+}
+
+func TestSearch(t *testing.T) {
+	m := testMirror(t)
+	tests := []struct {
+		query string
+		want  []string // URIs of the first results, in order
+	}{
+		// The album's own name outranks its tracks, which only match it
+		// through their album.
+		{"abbey road", []string{"spotify:album:abbey", "spotify:track:come", "spotify:track:something"}},
+		{"abbey", []string{"spotify:album:abbey", "spotify:track:come", "spotify:track:something"}},
+		{"road trip", []string{"spotify:playlist:trip"}},
+		// Typos and partly typed words.
+		{"beatels", []string{"spotify:artist:beatles", "spotify:track:come", "spotify:track:something", "spotify:album:abbey"}},
+		{"come tog", []string{"spotify:track:come"}},
+		{"somthing", []string{"spotify:track:something"}},
+		// Apostrophes and accents fold away.
+		{"dont stop", []string{"spotify:track:dont"}},
+		{"CAFÉ", []string{"spotify:track:cafe"}},
+		{"cafe", []string{"spotify:track:cafe"}},
+		// Words may match different fields, but every one must match.
+		{"queen now", []string{"spotify:track:dont"}},
+		{"beatles something", []string{"spotify:track:something"}},
+		{"abbey zeppelin", nil},
+		{"...", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, r := range m.Search(tt.query, 0) {
+			got = append(got, r.URI)
+		}
+		if len(got) < len(tt.want) || !slices.Equal(got[:len(tt.want)], tt.want) || (tt.want == nil && got != nil) {
+			t.Errorf("Search(%q) = %q, want it to start with %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchLimitAndTies(t *testing.T) {
+	m := testMirror(t)
+	res := m.Search("beatles", 0)
+	if len(res) < 3 {
+		t.Fatalf("Search(beatles) = %d results, want at least 3", len(res))
+	}
+	// The artist matches by name; the album and the tracks by artist, equally
+	// well, so the tie goes to tracks first, then by name.
+	var kinds []Kind
+	for _, r := range res {
+		kinds = append(kinds, r.Kind)
+	}
+	if want := []Kind{KindArtist, KindTrack, KindTrack, KindAlbum}; !slices.Equal(kinds, want) {
+		t.Errorf("kinds = %q, want %q", kinds, want)
+	}
+	if res[1].Name != "Come Together" || res[2].Name != "Something" {
+		t.Errorf("tracks = %q, %q; want them by name", res[1].Name, res[2].Name)
+	}
+	if got := m.Search("beatles", 2); len(got) != 2 || got[0].URI != res[0].URI {
+		t.Errorf("Search with limit 2 = %d results", len(got))
+	}
+
+	// The index follows updates to the mirror.
+	m.update(func(d *mirrorData) { d.Albums = nil })
+	for _, r := range m.Search("abbey", 0) {
+		if r.Kind == KindAlbum {
+			t.Error("a removed album is still found")
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Package library keeps a local mirror of the user's Spotify library (Liked
+// Songs, saved albums, playlists and their items) and the sync engine that
+// keeps it up to date in the background, so views and commands can read the
+// library instantly instead of paging through the Web API each time.
+package library
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"cli_spotify/internal/webapi"
+)
+
+// mirrorVersion is bumped when the file format changes incompatibly; older
+// files are then ignored and rebuilt by the next sync.
+const mirrorVersion = 2 // 2: tracks carry playability and relinking
+
+// Mirror is the local copy of the library. It is safe for concurrent use.
+// Returned slices are shared and must not be modified: the syncer replaces
+// them wholesale instead of editing them in place.
+type Mirror struct {
+	path string
+
+	mu   sync.RWMutex
+	data mirrorData
+	gen  int // bumped on every update
+
+	indexMu sync.Mutex
+	index   *index // search index, rebuilt on demand when gen moves on
+}
+
+// mirrorData is the on-disk form of the mirror.
+type mirrorData struct {
+	Version   int                      `json:"version"`
+	Synced    time.Time                `json:"synced"`
+	Liked     []webapi.Track           `json:"liked"`
+	Albums    []webapi.Album           `json:"albums"`
+	Playlists []webapi.Playlist        `json:"playlists"`
+	Items     map[string]playlistItems `json:"items"` // by playlist URI
+}
+
+// playlistItems are a playlist's items as of one snapshot.
+type playlistItems struct {
+	Snapshot string `json:"snapshot"`
+	// Unavailable marks playlists whose items the API refused to list
+	// (other users' playlists for development-mode apps).
+	Unavailable bool           `json:"unavailable,omitempty"`
+	Tracks      []webapi.Track `json:"tracks"`
+}
+
+// Open loads the mirror stored at path. A missing or outdated file yields an
+// empty mirror that the first sync fills.
+func Open(path string) (*Mirror, error) {
+	m := &Mirror{path: path, data: mirrorData{Version: mirrorVersion}}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var d mirrorData
+	if json.Unmarshal(raw, &d) == nil && d.Version == mirrorVersion {
+		m.data = d
+	}
+	return m, nil
+}
+
+// Synced returns when the last complete sync finished, or the zero time if
+// the mirror has never been filled.
+func (m *Mirror) Synced() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.data.Synced
+}
+
+// Liked returns the mirrored Liked Songs, newest first.
+func (m *Mirror) Liked() []webapi.Track {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.data.Liked
+}
+
+// Albums returns the mirrored saved albums, newest first.
+func (m *Mirror) Albums() []webapi.Album {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.data.Albums
+}
+
+// Playlists returns the mirrored playlists in the user's order.
+func (m *Mirror) Playlists() []webapi.Playlist {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.data.Playlists
+}
+
+// PlaylistItems returns the mirrored items of a playlist and the number of
+// items the playlist holds, which is larger when some could not be mirrored
+// (local files, unavailable tracks). ok is false when they are not mirrored,
+// are unavailable, or belong to an older snapshot than the mirrored playlist
+// itself.
+func (m *Mirror) PlaylistItems(uri string) (tracks []webapi.Track, total int, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	items, found := m.data.Items[uri]
+	if !found || items.Unavailable {
+		return nil, 0, false
+	}
+	for _, p := range m.data.Playlists {
+		if p.URI == uri {
+			return items.Tracks, p.Tracks.Total, p.SnapshotID == items.Snapshot
+		}
+	}
+	return nil, 0, false
+}
+
+// snapshot returns the snapshot the mirrored items of a playlist belong to.
+func (m *Mirror) snapshot(uri string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	items, ok := m.data.Items[uri]
+	return items.Snapshot, ok
+}
+
+func (m *Mirror) update(fn func(d *mirrorData)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fn(&m.data)
+	m.gen++
+}
+
+// save writes the mirror atomically (temporary file and rename).
+func (m *Mirror) save() error {
+	m.mu.RLock()
+	data, err := json.Marshal(m.data)
+	m.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(m.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".library-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), m.path)
+}
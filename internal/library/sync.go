@@ -0,0 +1,323 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"cli_spotify/internal/webapi"
+)
+
+// playlistWorkers is how many playlists have their items fetched at once.
+// The Web API client caps requests in flight anyway; this only bounds how
+// many playlists are in progress.
+const playlistWorkers = 4
+
+// Progress reports the state of a sync pass.
+type Progress struct {
+	Section  string // "liked songs", "albums", "playlists", "playlist items"
+	Done     int    // items of Section completed so far
+	Total    int
+	Changed  bool // the mirror changed; readers should reload it
+	Finished bool // the pass is over (successfully unless Err is set)
+	Err      error
+}
+
+// String describes the progress for a status line.
+func (p Progress) String() string {
+	switch {
+	case p.Finished && p.Err != nil:
+		return "Library sync failed: " + p.Err.Error()
+	case p.Finished:
+		return "Library synced"
+	case p.Total > 0:
+		return fmt.Sprintf("Syncing %s %d/%d", p.Section, p.Done, p.Total)
+	}
+	return "Syncing " + p.Section + "..."
+}
+
+// Syncer keeps a Mirror up to date: once at Start, then every interval and
+// whenever SyncNow is called.
+type Syncer struct {
+	web      *webapi.Client
+	mirror   *Mirror
+	history  *History // nil unless UseHistory was called
+	interval time.Duration
+
+	progress chan Progress
+	trigger  chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	passMu   sync.Mutex // one pass at a time
+}
+
+// NewSyncer creates a Syncer for mirror. It does nothing until Start or Sync.
+func NewSyncer(web *webapi.Client, mirror *Mirror, interval time.Duration) *Syncer {
+	return &Syncer{
+		web:      web,
+		mirror:   mirror,
+		interval: interval,
+		progress: make(chan Progress, 64),
+		trigger:  make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Mirror returns the mirror the syncer maintains.
+func (s *Syncer) Mirror() *Mirror {
+	return s.mirror
+}
+
+// UseHistory makes every pass record new versions of the playlists in h.
+// It must be called before Start or Sync.
+func (s *Syncer) UseHistory(h *History) {
+	s.history = h
+}
+
+// Progress delivers progress reports. Reports are dropped rather than
+// blocking the sync when nobody is reading.
+func (s *Syncer) Progress() <-chan Progress {
+	return s.progress
+}
+
+// Start runs a pass now and then periodically in the background until Stop.
+func (s *Syncer) Start() {
+	go func() {
+		for {
+			_ = s.Sync()
+			timer := time.NewTimer(s.interval)
+			select {
+			case <-s.stop:
+				timer.Stop()
+				return
+			case <-s.trigger:
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+	}()
+}
+
+// SyncNow asks the background loop for an immediate pass.
+func (s *Syncer) SyncNow() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Stop ends the background loop after the current pass. It may be called
+// more than once.
+func (s *Syncer) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// Sync runs one pass: Liked Songs, saved albums and the playlist list are
+// fetched concurrently, then the items of every playlist whose snapshot
+// changed. Each part is applied to the mirror as soon as it arrives.
+func (s *Syncer) Sync() error {
+	s.passMu.Lock()
+	defer s.passMu.Unlock()
+
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		errs     []error
+		lists    bool // the playlist list arrived
+		playlist []webapi.Playlist
+	)
+	fail := func(section string, err error) {
+		errMu.Lock()
+		errs = append(errs, fmt.Errorf("%s: %w", section, err))
+		errMu.Unlock()
+	}
+
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		s.report(Progress{Section: "liked songs"})
+		have := s.mirror.Liked()
+		liked, changed, err := syncList(s.web.SavedTracksPager(), have, func(t webapi.Track) string { return t.URI })
+		if err != nil {
+			fail("liked songs", err)
+			return
+		}
+		if changed {
+			s.mirror.update(func(d *mirrorData) { d.Liked = liked })
+			s.report(Progress{Section: "liked songs", Done: len(liked), Total: len(liked), Changed: true})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		s.report(Progress{Section: "albums"})
+		have := s.mirror.Albums()
+		albums, changed, err := syncList(s.web.SavedAlbumsPager(), have, func(a webapi.Album) string { return a.URI })
+		if err != nil {
+			fail("albums", err)
+			return
+		}
+		if changed {
+			s.mirror.update(func(d *mirrorData) { d.Albums = albums })
+			s.report(Progress{Section: "albums", Done: len(albums), Total: len(albums), Changed: true})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		s.report(Progress{Section: "playlists"})
+		pls, err := s.web.UserPlaylistsPager().Remaining()
+		if err != nil {
+			fail("playlists", err)
+			return
+		}
+		playlist, lists = pls, true
+		s.mirror.update(func(d *mirrorData) { d.Playlists = pls })
+		s.report(Progress{Section: "playlists", Done: len(pls), Total: len(pls), Changed: true})
+	}()
+	wg.Wait()
+
+	if lists {
+		if err := s.syncPlaylistItems(playlist); err != nil {
+			fail("playlist items", err)
+		}
+	}
+
+	err := errors.Join(errs...)
+	if err == nil {
+		s.mirror.update(func(d *mirrorData) { d.Synced = time.Now() })
+	}
+	if serr := s.mirror.save(); serr != nil && err == nil {
+		err = fmt.Errorf("saving library mirror: %w", serr)
+	}
+	s.report(Progress{Finished: true, Err: err})
+	return err
+}
+
+// syncPlaylistItems refetches the items of playlists whose snapshot differs
+// from the mirrored one and forgets playlists the user no longer has. With a
+// History, each playlist's current items are recorded as a version unless
+// they already are.
+func (s *Syncer) syncPlaylistItems(playlists []webapi.Playlist) error {
+	keep := make(map[string]bool, len(playlists))
+	var (
+		stale []webapi.Playlist
+		errs  []error
+	)
+	for _, p := range playlists {
+		keep[p.URI] = true
+		if snap, ok := s.mirror.snapshot(p.URI); !ok || snap != p.SnapshotID {
+			stale = append(stale, p)
+		} else if tracks, _, ok := s.mirror.PlaylistItems(p.URI); ok {
+			// Mirrored before history was kept, or recorded already.
+			errs = append(errs, s.record(p, tracks))
+		}
+	}
+	s.mirror.update(func(d *mirrorData) {
+		for uri := range d.Items {
+			if !keep[uri] {
+				delete(d.Items, uri)
+			}
+		}
+	})
+	if len(stale) == 0 {
+		return errors.Join(errs...)
+	}
+
+	work := make(chan webapi.Playlist)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+	)
+	for range min(playlistWorkers, len(stale)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range work {
+				items := playlistItems{Snapshot: p.SnapshotID}
+				tracks, err := s.web.PlaylistTracksPager(p.URI).Remaining()
+				var status *webapi.StatusError
+				switch {
+				case errors.As(err, &status) && status.Status == http.StatusForbidden:
+					items.Unavailable = true
+				case err != nil:
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+					mu.Unlock()
+					continue
+				default:
+					items.Tracks = tracks
+					if err := s.record(p, tracks); err != nil {
+						mu.Lock()
+						errs = append(errs, err)
+						mu.Unlock()
+					}
+				}
+				s.mirror.update(func(d *mirrorData) {
+					if d.Items == nil {
+						d.Items = make(map[string]playlistItems)
+					}
+					d.Items[p.URI] = items
+				})
+				mu.Lock()
+				done++
+				n := done
+				mu.Unlock()
+				s.report(Progress{Section: "playlist items", Done: n, Total: len(stale), Changed: true})
+			}
+		}()
+	}
+	for _, p := range stale {
+		work <- p
+	}
+	close(work)
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// record adds the items of p to the history, if one is kept.
+func (s *Syncer) record(p webapi.Playlist, tracks []webapi.Track) error {
+	if s.history == nil {
+		return nil
+	}
+	if _, err := s.history.Record(p, tracks); err != nil {
+		return fmt.Errorf("recording history of %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+// syncList refreshes a newest-first list. If the first page matches the start
+// of the mirrored list and the total is unchanged, nothing was added or
+// removed and the rest is not fetched; otherwise the remaining pages are
+// fetched concurrently.
+func syncList[T any](p *webapi.Pager[T], have []T, key func(T) string) ([]T, bool, error) {
+	first, err := p.Next()
+	if err != nil {
+		return nil, false, err
+	}
+	if p.Total() == len(have) && len(first) <= len(have) {
+		same := true
+		for i, it := range first {
+			if key(it) != key(have[i]) {
+				same = false
+				break
+			}
+		}
+		if same {
+			return have, false, nil
+		}
+	}
+	rest, err := p.Remaining()
+	if err != nil {
+		return nil, false, err
+	}
+	return append(first, rest...), true, nil
+}
+
+func (s *Syncer) report(p Progress) {
+	select {
+	case s.progress <- p:
+	default:
+	}
+}
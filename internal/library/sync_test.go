@@ -0,0 +1,281 @@
+package library
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"cli_spotify/internal/webapi"
+	"cli_spotify/internal/webapi/webapitest"
+)
+
+// allScopes is every scope the Web API client asks for; a saved token with
+// fewer would start an interactive login.
+const allScopes = "user-read-playback-state user-modify-playback-state user-read-currently-playing playlist-read-private playlist-read-collaborative playlist-modify-public playlist-modify-private user-library-read user-library-modify user-read-recently-played user-top-read user-read-playback-position user-read-private"
+
+// newTestSyncer returns a syncer for an empty mirror of srv's library.
+func newTestSyncer(t *testing.T, srv *webapitest.Server) *Syncer {
+	t.Helper()
+	dir := t.TempDir()
+	store := webapi.NewFileStore(filepath.Join(dir, "token.json"))
+	access, refresh, expiry := srv.IssueToken(time.Hour, allScopes)
+	if err := store.Save(&webapi.Token{AccessToken: access, RefreshToken: refresh, Expiry: expiry, Scope: allScopes}); err != nil {
+		t.Fatal(err)
+	}
+	auth := webapi.NewAuthenticator(webapitest.ClientID, "http://127.0.0.1:8888/callback", store)
+	auth.UseEndpoints(webapi.Endpoints{API: srv.APIBase(), Authorize: srv.AuthorizeURL(), Token: srv.TokenURL()})
+	web, err := webapi.NewClient(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(web.Close)
+	mirror, err := Open(filepath.Join(dir, "library.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewSyncer(web, mirror, time.Hour)
+}
+
+// requests counts the requests srv has received for each method and path.
+func requests(srv *webapitest.Server) map[string]int {
+	n := make(map[string]int)
+	for _, r := range srv.Requests() {
+		n[r]++
+	}
+	return n
+}
+
+// since returns how many more requests for each method and path srv has
+// received than before.
+func since(srv *webapitest.Server, before map[string]int) map[string]int {
+	n := requests(srv)
+	for r, c := range before {
+		if n[r] -= c; n[r] == 0 {
+			delete(n, r)
+		}
+	}
+	return n
+}
+
+func uris(tracks []webapi.Track) []string {
+	out := make([]string, len(tracks))
+	for i, t := range tracks {
+		out[i] = t.URI
+	}
+	return out
+}
+
+func TestSyncFillsMirror(t *testing.T) {
+	srv := webapitest.New()
+	defer srv.Close()
+	srv.Playlists[1].Forbidden = true
+	s := newTestSyncer(t, srv)
+
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	m := s.Mirror()
+	if m.Synced().IsZero() {
+		t.Error("the mirror is not marked synced")
+	}
+	if got, want := uris(m.Liked()), []string{"spotify:track:liked1", "spotify:track:liked2", "spotify:track:liked3"}; !slices.Equal(got, want) {
+		t.Errorf("liked = %q, want %q", got, want)
+	}
+	if n := len(m.Albums()); n != 2 {
+		t.Errorf("%d albums, want 2", n)
+	}
+	if n := len(m.Playlists()); n != 2 {
+		t.Errorf("%d playlists, want 2", n)
+	}
+	tracks, total, ok := m.PlaylistItems("spotify:playlist:pl1")
+	if want := []string{"spotify:track:p1t1", "spotify:episode:ep1", "spotify:track:p1t2"}; !ok || total != 3 || !slices.Equal(uris(tracks), want) {
+		t.Errorf("pl1 items = %q (of %d), %v; want %q", uris(tracks), total, ok, want)
+	}
+
+	// The other user's playlist answered 403: it is marked unavailable, not
+	// a failure of the pass, and not asked for again while unchanged.
+	if _, _, ok := m.PlaylistItems("spotify:playlist:pl2"); ok {
+		t.Error("the forbidden playlist has mirrored items")
+	}
+	before := requests(srv)
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := since(srv, before)["GET /playlists/pl2/items"]; n != 0 {
+		t.Errorf("the forbidden playlist was fetched %d times again", n)
+	}
+
+	// The mirror is saved: reopening it finds the same library.
+	reopened, err := Open(m.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(uris(reopened.Liked()), uris(m.Liked())) || !reopened.Synced().Equal(m.Synced()) {
+		t.Error("the reopened mirror differs")
+	}
+}
+
+func TestSyncSkipsUnchangedParts(t *testing.T) {
+	srv := webapitest.New()
+	defer srv.Close()
+	srv.Liked = nil
+	for i := range 120 { // three pages
+		id := "l" + strconv.Itoa(i)
+		srv.Liked = append(srv.Liked, webapitest.NewTrack(id, id, "Artist"))
+	}
+	s := newTestSyncer(t, srv)
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing changed: one page of each list, and no playlist items.
+	before := requests(srv)
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"GET /me/tracks": 1, "GET /me/albums": 1, "GET /me/playlists": 1}
+	if got := since(srv, before); !maps.Equal(got, want) {
+		t.Errorf("requests for an unchanged library = %v, want %v", got, want)
+	}
+
+	// A newly liked track changes the total: every page is fetched again.
+	srv.Liked = append([]webapitest.Track{webapitest.NewTrack("new", "New", "Artist")}, srv.Liked...)
+	before = requests(srv)
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := since(srv, before)["GET /me/tracks"]; n != 3 {
+		t.Errorf("%d liked pages fetched after a change, want 3", n)
+	}
+	if liked := s.Mirror().Liked(); len(liked) != 121 || liked[0].URI != "spotify:track:new" {
+		t.Errorf("mirror has %d liked tracks starting with %q, want 121 starting with the new one", len(liked), liked[0].URI)
+	}
+
+	// Same total, different first page (one unliked, another liked).
+	srv.Liked[0] = webapitest.NewTrack("swapped", "Swapped", "Artist")
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if liked := s.Mirror().Liked(); liked[0].URI != "spotify:track:swapped" {
+		t.Errorf("first liked track = %q after a swap, want swapped", liked[0].URI)
+	}
+
+	// An edit gives the playlist a new snapshot, so only it is fetched.
+	pl := srv.Playlists[0]
+	pl.Tracks = append(pl.Tracks, webapitest.NewTrack("added", "Added", "Artist"))
+	pl.Version++
+	srv.Playlists[0] = pl
+	before = requests(srv)
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	got := since(srv, before)
+	if got["GET /playlists/pl1/items"] != 1 || got["GET /playlists/pl2/items"] != 0 {
+		t.Errorf("playlist item requests = %v, want only pl1's", got)
+	}
+	if tracks, total, ok := s.Mirror().PlaylistItems("spotify:playlist:pl1"); !ok || len(tracks) != 4 || total != 4 {
+		t.Errorf("pl1 has %d of %d items mirrored (ok %v), want 4", len(tracks), total, ok)
+	}
+}
+
+func TestSyncFetchesManyPlaylists(t *testing.T) {
+	srv := webapitest.New()
+	defer srv.Close()
+	const n = 3*playlistWorkers + 1
+	srv.Playlists = nil
+	for i := range n {
+		id := "pl" + strconv.Itoa(i)
+		srv.Playlists = append(srv.Playlists, webapitest.Playlist{ID: id, Name: id, Owner: "tester", Tracks: []webapitest.Track{
+			webapitest.NewTrack(id+"t", "Track of "+id, "Artist"),
+		}})
+	}
+	s := newTestSyncer(t, srv)
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := requests(srv)
+	done := map[int]bool{}
+	for range len(s.Progress()) {
+		if r := <-s.Progress(); r.Section == "playlist items" {
+			done[r.Done] = true
+			if r.Total != n {
+				t.Errorf("progress total = %d, want %d", r.Total, n)
+			}
+		}
+	}
+	for i := range n {
+		id := "pl" + strconv.Itoa(i)
+		if got["GET /playlists/"+id+"/items"] != 1 {
+			t.Errorf("%s fetched %d times, want once", id, got["GET /playlists/"+id+"/items"])
+		}
+		tracks, _, ok := s.Mirror().PlaylistItems("spotify:playlist:" + id)
+		if !ok || len(tracks) != 1 || tracks[0].URI != "spotify:track:"+id+"t" {
+			t.Errorf("%s mirrored as %q, %v", id, uris(tracks), ok)
+		}
+		if !done[i+1] {
+			t.Errorf("no progress report for %d of %d playlists", i+1, n)
+		}
+	}
+}
+
+func TestSyncRecordsHistory(t *testing.T) {
+	srv := webapitest.New()
+	defer srv.Close()
+	s := newTestSyncer(t, srv)
+	// A first pass without history: the mirrored items are recorded by the
+	// first pass that keeps one, though they are not fetched again.
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	h := OpenHistory(t.TempDir())
+	s.UseHistory(h)
+
+	versions := func() int {
+		t.Helper()
+		v, err := h.Versions("spotify:playlist:pl1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(v)
+	}
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := versions(); n != 1 {
+		t.Fatalf("%d versions after syncing, want 1", n)
+	}
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := versions(); n != 1 {
+		t.Errorf("%d versions after an unchanged sync, want 1", n)
+	}
+
+	pl := srv.Playlists[0]
+	pl.Tracks = pl.Tracks[1:]
+	pl.Version++
+	srv.Playlists[0] = pl
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := versions(); n != 2 {
+		t.Fatalf("%d versions after an edit, want 2", n)
+	}
+	v, err := h.Version("spotify:playlist:pl1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"spotify:episode:ep1", "spotify:track:p1t2"}; !slices.Equal(v.Items, want) {
+		t.Errorf("latest version = %q, want %q", v.Items, want)
+	}
+}
+
+func TestStopTwice(t *testing.T) {
+	s := NewSyncer(nil, nil, 0)
+	s.Stop()
+	s.Stop()
+}
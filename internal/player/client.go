@@ -11,33 +11,39 @@ import (
 
 // Client is an HTTP client for the go-librespot REST API.
 type Client struct {
+	ep      Endpoint
 	baseURL string
 	http    *http.Client
 }
 
-// NewClient creates a new Client targeting the given port.
+// NewClient creates a new Client targeting the local daemon on the given port.
 func NewClient(port int) *Client {
+	return NewClientFor(LocalEndpoint(port))
+}
+
+// NewClientFor creates a Client for a local or remote daemon endpoint.
+func NewClientFor(ep Endpoint) *Client {
 	return &Client{
-		baseURL: fmt.Sprintf("http://localhost:%d", port),
-		http:    &http.Client{Timeout: 5 * time.Second},
+		ep:      ep,
+		baseURL: ep.BaseURL,
+		http:    &http.Client{Timeout: 5 * time.Second, Transport: ep.transport()},
 	}
 }
 
+// Health reports whether the daemon API is reachable via GET /.
+func (c *Client) Health() error {
+	return c.getJSON("/", nil)
+}
+
 // Status fetches the current playback status from GET /status.
+//
+// go-librespot answers 204 No Content when no session is active yet; that is
+// reported as a stopped status rather than an error.
 func (c *Client) Status() (*Status, error) {
-	resp, err := c.http.Get(c.baseURL + "/status")
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status endpoint returned %d", resp.StatusCode)
-	}
-
 	var s Status
-	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
-		return nil, fmt.Errorf("decoding status: %w", err)
+	s.Stopped = true
+	if err := c.getJSON("/status", &s); err != nil {
+		return nil, err
 	}
 	return &s, nil
 }
@@ -47,16 +53,43 @@ func (c *Client) PlayPause() error {
 	return c.postEmpty("/player/playpause")
 }
 
+// Pause pauses playback via POST /player/pause.
+func (c *Client) Pause() error {
+	return c.postEmpty("/player/pause")
+}
+
+// Resume resumes paused playback via POST /player/resume.
+func (c *Client) Resume() error {
+	return c.postEmpty("/player/resume")
+}
+
 // Next skips to the next track via POST /player/next.
 func (c *Client) Next() error {
 	return c.postJSON("/player/next", map[string]any{})
 }
 
+// NextTo skips forward to a specific track URI in the upcoming tracks or queue
+// via POST /player/next.
+func (c *Client) NextTo(uri string) error {
+	return c.postJSON("/player/next", map[string]any{
+		"uri": uri,
+	})
+}
+
 // Prev goes to the previous track via POST /player/prev.
 func (c *Client) Prev() error {
 	return c.postEmpty("/player/prev")
 }
 
+// Volume fetches the current volume and its maximum via GET /player/volume.
+func (c *Client) Volume() (*Volume, error) {
+	var v Volume
+	if err := c.getJSON("/player/volume", &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
 // SetVolume sets the absolute volume (0–100) via POST /player/volume.
 func (c *Client) SetVolume(vol int) error {
 	if vol < 0 {
@@ -87,6 +120,15 @@ func (c *Client) Seek(ms int) error {
 	})
 }
 
+// SeekRelative moves the playback position by deltaMs milliseconds (negative
+// to rewind) via POST /player/seek.
+func (c *Client) SeekRelative(deltaMs int) error {
+	return c.postJSON("/player/seek", map[string]any{
+		"position": deltaMs,
+		"relative": true,
+	})
+}
+
 // Play starts playback of a Spotify URI (track, album, playlist, or artist) via
 // POST /player/play. skipToURI optionally selects a track within a context
 // (playlist/album); pass "" to start from the beginning.
@@ -101,6 +143,85 @@ func (c *Client) Play(uri, skipToURI string, paused bool) error {
 	return c.postJSON("/player/play", body)
 }
 
+// PlayFrom starts playback of a single track or episode at positionMs, e.g.
+// to resume a podcast episode. It loads the item paused, waits for the daemon
+// to report it loaded, seeks and then resumes, so the beginning is never
+// heard. Seeking any earlier would act on the previous item, or be lost.
+func (c *Client) PlayFrom(uri string, positionMs int) error {
+	if positionMs <= 0 {
+		return c.Play(uri, "", false)
+	}
+	events, err := NewEventHandlerFor(c.ep)
+	if err != nil {
+		return err
+	}
+	defer events.Close()
+	events.Start()
+
+	if err := c.Play(uri, "", true); err != nil {
+		return err
+	}
+	if err := waitLoaded(events.Ch, uri, loadTimeout); err != nil {
+		return err
+	}
+	if err := c.Seek(positionMs); err != nil {
+		return err
+	}
+	return c.Resume()
+}
+
+// loadTimeout bounds how long PlayFrom waits for an item to load.
+const loadTimeout = 10 * time.Second
+
+// waitLoaded waits for the metadata or paused event that shows uri has been
+// loaded, ignoring events about whatever played before.
+func waitLoaded(ch <-chan Event, uri string, timeout time.Duration) error {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return fmt.Errorf("event stream closed while loading %s", uri)
+			}
+			var data struct {
+				URI string `json:"uri"`
+			}
+			if (ev.Type == "metadata" || ev.Type == "paused") && json.Unmarshal(ev.Data, &data) == nil && data.URI == uri {
+				return nil
+			}
+		case <-deadline:
+			return fmt.Errorf("timed out waiting for %s to load", uri)
+		}
+	}
+}
+
+// PlayShuffled starts playback of a context URI with shuffle enabled. Shuffle
+// is set first so the daemon picks a random starting track for the new context.
+func (c *Client) PlayShuffled(uri string) error {
+	if err := c.SetShuffle(true); err != nil {
+		return err
+	}
+	return c.Play(uri, "", false)
+}
+
+// PlayURIs plays an ad-hoc list of track URIs. go-librespot has no endpoint
+// for a URI list, so the first track is played directly and the rest are
+// appended to the queue in order.
+func (c *Client) PlayURIs(uris []string) error {
+	if len(uris) == 0 {
+		return fmt.Errorf("no URIs to play")
+	}
+	if err := c.Play(uris[0], "", false); err != nil {
+		return err
+	}
+	for _, uri := range uris[1:] {
+		if err := c.AddToQueue(uri); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // AddToQueue appends a track URI to the playback queue via POST
 // /player/add_to_queue.
 func (c *Client) AddToQueue(uri string) error {
@@ -130,6 +251,27 @@ func (c *Client) SetRepeatTrack(on bool) error {
 	})
 }
 
+// getJSON sends a GET and decodes the JSON response into out. A 204 No Content
+// response leaves out untouched; out may be nil to discard the body.
+func (c *Client) getJSON(path string, out any) error {
+	resp, err := c.http.Get(c.baseURL + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GET %s returned %d: %s", path, resp.StatusCode, b)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return nil
+}
+
 // postEmpty sends a POST with no body.
 func (c *Client) postEmpty(path string) error {
 	resp, err := c.http.Post(c.baseURL+path, "application/json", nil)
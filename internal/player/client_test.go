@@ -0,0 +1,269 @@
+package player
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// request is one call received by the stub daemon.
+type request struct {
+	Method string
+	Path   string
+	Body   map[string]any // nil when the request had no body
+}
+
+// stubDaemon records every request and answers from a table of canned
+// responses by "METHOD /path"; unlisted requests get 200 with no body.
+type stubDaemon struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	requests  []request
+	responses map[string]stubResponse
+
+	// played, when set, receives the URI of every play request, and the
+	// /events stream answers the first with metadata events: one about the
+	// previous track, then one about the new one a moment later. Events are
+	// recorded as requests with method "EVENT".
+	played chan string
+}
+
+type stubResponse struct {
+	status int
+	body   string
+}
+
+func newStubDaemon(t *testing.T, responses map[string]stubResponse) (*stubDaemon, *Client) {
+	t.Helper()
+	s := &stubDaemon{responses: responses}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s, NewClientFor(Endpoint{BaseURL: s.URL})
+}
+
+func (s *stubDaemon) serve(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/events" {
+		s.serveEvents(w, r)
+		return
+	}
+	req := request{Method: r.Method, Path: r.URL.Path}
+	if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+		if err := json.Unmarshal(data, &req.Body); err != nil {
+			http.Error(w, "bad JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	resp, ok := s.responses[r.Method+" "+r.URL.Path]
+	s.mu.Unlock()
+	if s.played != nil && req.Method == "POST" && req.Path == "/player/play" {
+		s.played <- req.Body["uri"].(string)
+	}
+	if !ok {
+		return
+	}
+	w.WriteHeader(resp.status)
+	io.WriteString(w, resp.body)
+}
+
+func (s *stubDaemon) serveEvents(w http.ResponseWriter, r *http.Request) {
+	var upgrader websocket.Upgrader
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	emit := func(uri string) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, request{Method: "EVENT", Path: "metadata", Body: map[string]any{"uri": uri}})
+		conn.WriteJSON(map[string]any{"type": "metadata", "data": map[string]any{"uri": uri}})
+	}
+	select {
+	case uri := <-s.played:
+		emit("spotify:track:previous")
+		time.Sleep(50 * time.Millisecond)
+		emit(uri)
+	case <-time.After(5 * time.Second):
+	}
+}
+
+func (s *stubDaemon) received() []request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]request(nil), s.requests...)
+}
+
+func TestClientRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(c *Client) error
+		want []request
+	}{
+		{"Health", (*Client).Health, []request{{Method: "GET", Path: "/"}}},
+		{"Pause", (*Client).Pause, []request{{Method: "POST", Path: "/player/pause"}}},
+		{"Resume", (*Client).Resume, []request{{Method: "POST", Path: "/player/resume"}}},
+		{"PlayPause", (*Client).PlayPause, []request{{Method: "POST", Path: "/player/playpause"}}},
+		{"Prev", (*Client).Prev, []request{{Method: "POST", Path: "/player/prev"}}},
+		{"Next", (*Client).Next, []request{{Method: "POST", Path: "/player/next", Body: map[string]any{}}}},
+		{
+			"NextTo",
+			func(c *Client) error { return c.NextTo("spotify:track:a") },
+			[]request{{Method: "POST", Path: "/player/next", Body: map[string]any{"uri": "spotify:track:a"}}},
+		},
+		{
+			"SetVolume clamps",
+			func(c *Client) error { return c.SetVolume(140) },
+			[]request{{Method: "POST", Path: "/player/volume", Body: map[string]any{"volume": 100.0, "relative": false}}},
+		},
+		{
+			"SetVolumeRelative",
+			func(c *Client) error { return c.SetVolumeRelative(-5) },
+			[]request{{Method: "POST", Path: "/player/volume", Body: map[string]any{"volume": -5.0, "relative": true}}},
+		},
+		{
+			"Seek",
+			func(c *Client) error { return c.Seek(42000) },
+			[]request{{Method: "POST", Path: "/player/seek", Body: map[string]any{"position": 42000.0, "relative": false}}},
+		},
+		{
+			"SeekRelative",
+			func(c *Client) error { return c.SeekRelative(-10000) },
+			[]request{{Method: "POST", Path: "/player/seek", Body: map[string]any{"position": -10000.0, "relative": true}}},
+		},
+		{
+			"Play with skip",
+			func(c *Client) error { return c.Play("spotify:album:x", "spotify:track:b", false) },
+			[]request{{Method: "POST", Path: "/player/play", Body: map[string]any{
+				"uri": "spotify:album:x", "paused": false, "skip_to_uri": "spotify:track:b",
+			}}},
+		},
+		{
+			"PlayShuffled",
+			func(c *Client) error { return c.PlayShuffled("spotify:playlist:p") },
+			[]request{
+				{Method: "POST", Path: "/player/shuffle_context", Body: map[string]any{"shuffle_context": true}},
+				{Method: "POST", Path: "/player/play", Body: map[string]any{"uri": "spotify:playlist:p", "paused": false}},
+			},
+		},
+		{
+			"PlayURIs",
+			func(c *Client) error {
+				return c.PlayURIs([]string{"spotify:track:a", "spotify:track:b", "spotify:track:c"})
+			},
+			[]request{
+				{Method: "POST", Path: "/player/play", Body: map[string]any{"uri": "spotify:track:a", "paused": false}},
+				{Method: "POST", Path: "/player/add_to_queue", Body: map[string]any{"uri": "spotify:track:b"}},
+				{Method: "POST", Path: "/player/add_to_queue", Body: map[string]any{"uri": "spotify:track:c"}},
+			},
+		},
+		{
+			"SetRepeatTrack",
+			func(c *Client) error { return c.SetRepeatTrack(true) },
+			[]request{{Method: "POST", Path: "/player/repeat_track", Body: map[string]any{"repeat_track": true}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub, c := newStubDaemon(t, nil)
+			if err := tt.call(c); err != nil {
+				t.Fatalf("call failed: %v", err)
+			}
+			if got := stub.received(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("requests = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlayFromSeeksOnceLoaded(t *testing.T) {
+	stub, c := newStubDaemon(t, nil)
+	stub.played = make(chan string, 1)
+
+	if err := c.PlayFrom("spotify:episode:e", 60000); err != nil {
+		t.Fatal(err)
+	}
+	want := []request{
+		{Method: "POST", Path: "/player/play", Body: map[string]any{"uri": "spotify:episode:e", "paused": true}},
+		{Method: "EVENT", Path: "metadata", Body: map[string]any{"uri": "spotify:track:previous"}},
+		{Method: "EVENT", Path: "metadata", Body: map[string]any{"uri": "spotify:episode:e"}},
+		{Method: "POST", Path: "/player/seek", Body: map[string]any{"position": 60000.0, "relative": false}},
+		{Method: "POST", Path: "/player/resume"},
+	}
+	if got := stub.received(); !reflect.DeepEqual(got, want) {
+		t.Errorf("requests = %+v, want %+v", got, want)
+	}
+}
+
+func TestPlayURIsEmpty(t *testing.T) {
+	stub, c := newStubDaemon(t, nil)
+	if err := c.PlayURIs(nil); err == nil {
+		t.Fatal("PlayURIs(nil) succeeded, want an error")
+	}
+	if got := stub.received(); len(got) != 0 {
+		t.Errorf("sent %+v, want no requests", got)
+	}
+}
+
+func TestVolume(t *testing.T) {
+	_, c := newStubDaemon(t, map[string]stubResponse{
+		"GET /player/volume": {http.StatusOK, `{"value": 30, "max": 100}`},
+	})
+	v, err := c.Volume()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *v != (Volume{Value: 30, Max: 100}) {
+		t.Errorf("Volume() = %+v, want {30 100}", *v)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	t.Run("playing", func(t *testing.T) {
+		_, c := newStubDaemon(t, map[string]stubResponse{
+			"GET /status": {http.StatusOK, `{"username": "me", "stopped": false, "paused": true, "volume": 12}`},
+		})
+		s, err := c.Status()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Stopped || !s.Paused || s.Username != "me" || s.Volume != 12 {
+			t.Errorf("Status() = %+v", s)
+		}
+	})
+	t.Run("no session", func(t *testing.T) {
+		_, c := newStubDaemon(t, map[string]stubResponse{
+			"GET /status": {http.StatusNoContent, ""},
+		})
+		s, err := c.Status()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.Stopped {
+			t.Errorf("Status() on 204 = %+v, want Stopped", s)
+		}
+	})
+}
+
+func TestClientErrors(t *testing.T) {
+	_, c := newStubDaemon(t, map[string]stubResponse{
+		"GET /":             {http.StatusServiceUnavailable, "starting"},
+		"POST /player/seek": {http.StatusBadRequest, "no track"},
+	})
+	if err := c.Health(); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Health() = %v, want a 503 error", err)
+	}
+	if err := c.Seek(1); err == nil || !strings.Contains(err.Error(), "no track") {
+		t.Errorf("Seek() = %v, want the daemon's message", err)
+	}
+}
@@ -0,0 +1,110 @@
+package player
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Endpoint describes how to reach a go-librespot daemon API: the local
+// daemon, a remote one on the LAN, or a remote one behind the authenticating
+// proxy (see daemon.Proxy).
+type Endpoint struct {
+	// BaseURL is the API root, e.g. http://localhost:3678 or
+	// https://pi.local:3679.
+	BaseURL string
+	// Token is an optional bearer token sent on every request, required when
+	// the daemon sits behind the authenticating proxy.
+	Token string
+	// TLS configures https/wss connections; nil uses the system defaults.
+	TLS *tls.Config
+}
+
+// LocalEndpoint returns the endpoint of a daemon on this machine.
+func LocalEndpoint(port int) Endpoint {
+	return Endpoint{BaseURL: fmt.Sprintf("http://localhost:%d", port)}
+}
+
+// RemoteEndpoint parses a daemon URL such as http://pi.local:3678. caFile is
+// an optional PEM bundle trusted in addition to the system roots, for proxies
+// using a self-signed certificate.
+func RemoteEndpoint(rawURL, token, caFile string) (Endpoint, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("invalid daemon URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return Endpoint{}, fmt.Errorf("daemon URL %q must start with http:// or https://", rawURL)
+	}
+	if u.Host == "" {
+		return Endpoint{}, fmt.Errorf("daemon URL %q has no host", rawURL)
+	}
+
+	ep := Endpoint{
+		BaseURL: strings.TrimSuffix(u.Scheme+"://"+u.Host+u.Path, "/"),
+		Token:   token,
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return Endpoint{}, fmt.Errorf("reading CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return Endpoint{}, fmt.Errorf("no certificates found in %s", caFile)
+		}
+		ep.TLS = &tls.Config{RootCAs: pool}
+	}
+	return ep, nil
+}
+
+// eventsURL returns the WebSocket URL of the /events stream.
+func (e Endpoint) eventsURL() string {
+	base := e.BaseURL
+	switch {
+	case strings.HasPrefix(base, "https://"):
+		base = "wss://" + strings.TrimPrefix(base, "https://")
+	case strings.HasPrefix(base, "http://"):
+		base = "ws://" + strings.TrimPrefix(base, "http://")
+	}
+	return base + "/events"
+}
+
+// header returns the headers to send on every request.
+func (e Endpoint) header() http.Header {
+	h := http.Header{}
+	if e.Token != "" {
+		h.Set("Authorization", "Bearer "+e.Token)
+	}
+	return h
+}
+
+// authTransport adds the endpoint's headers to each request.
+type authTransport struct {
+	header http.Header
+	base   http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.header {
+		req.Header[k] = v
+	}
+	return t.base.RoundTrip(req)
+}
+
+// transport builds the RoundTripper for the endpoint.
+func (e Endpoint) transport() http.RoundTripper {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	if e.TLS != nil {
+		base.TLSClientConfig = e.TLS
+	}
+	return &authTransport{header: e.header(), base: base}
+}
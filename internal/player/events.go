@@ -10,13 +10,21 @@ import (
 // EventHandler connects to the go-librespot WebSocket event stream.
 type EventHandler struct {
 	conn *websocket.Conn
+	rec  *Recorder
 	Ch   chan Event
 }
 
 // NewEventHandler connects to ws://localhost:{port}/events.
 func NewEventHandler(port int) (*EventHandler, error) {
-	url := fmt.Sprintf("ws://localhost:%d/events", port)
-	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	return NewEventHandlerFor(LocalEndpoint(port))
+}
+
+// NewEventHandlerFor connects to the /events stream of a local or remote
+// daemon endpoint.
+func NewEventHandlerFor(ep Endpoint) (*EventHandler, error) {
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = ep.TLS
+	conn, _, err := dialer.Dial(ep.eventsURL(), ep.header())
 	if err != nil {
 		return nil, fmt.Errorf("connecting to events WebSocket: %w", err)
 	}
@@ -26,6 +34,12 @@ func NewEventHandler(port int) (*EventHandler, error) {
 	}, nil
 }
 
+// Record tees every raw message received on the stream into rec. It must be
+// called before Start.
+func (h *EventHandler) Record(rec *Recorder) {
+	h.rec = rec
+}
+
 // Start begins reading events in a background goroutine.
 // Events are sent to h.Ch. The goroutine exits when the connection closes.
 func (h *EventHandler) Start() {
@@ -36,6 +50,9 @@ func (h *EventHandler) Start() {
 			if err != nil {
 				return
 			}
+			if h.rec != nil {
+				_ = h.rec.Event(msg)
+			}
 			var ev Event
 			if err := json.Unmarshal(msg, &ev); err != nil {
 				continue
@@ -0,0 +1,64 @@
+package player
+
+import "time"
+
+// Position models the playback position as the last daemon-reported value
+// plus the local monotonic time the report was received; it is not a
+// timestamp from the daemon, so transport delay is not corrected for. The
+// current position is extrapolated on demand instead of being accumulated
+// tick by tick, so it does not drift after suspend, UI lag or a stalled
+// event stream.
+//
+// The zero value is a stopped position at 0.
+type Position struct {
+	base     time.Duration
+	at       time.Time // carries a monotonic clock reading
+	running  bool
+	duration time.Duration
+}
+
+// Set records a reported position observed at the given time.
+func (p *Position) Set(pos time.Duration, at time.Time) {
+	p.base, p.at = pos, at
+}
+
+// SetDuration sets the track length, which bounds the extrapolation.
+func (p *Position) SetDuration(d time.Duration) {
+	p.duration = d
+}
+
+// Duration returns the track length.
+func (p *Position) Duration() time.Duration {
+	return p.duration
+}
+
+// SetRunning starts or freezes the clock at the given time. Freezing keeps the
+// position reached so far.
+func (p *Position) SetRunning(running bool, at time.Time) {
+	if running == p.running {
+		return
+	}
+	p.base = p.At(at)
+	p.at = at
+	p.running = running
+}
+
+// Running reports whether the position is advancing.
+func (p *Position) Running() bool {
+	return p.running
+}
+
+// At returns the extrapolated position at now, clamped to [0, duration].
+func (p *Position) At(now time.Time) time.Duration {
+	pos := p.base
+	if p.running && !p.at.IsZero() {
+		pos += now.Sub(p.at)
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	if p.duration > 0 && pos > p.duration {
+		pos = p.duration
+	}
+	return pos
+}
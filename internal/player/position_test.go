@@ -0,0 +1,60 @@
+package player
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPosition(t *testing.T) {
+	t0 := time.Now()
+	at := func(d time.Duration) time.Time { return t0.Add(d) }
+
+	var p Position
+	if got := p.At(at(time.Minute)); got != 0 {
+		t.Errorf("zero value At() = %v, want 0", got)
+	}
+
+	p.SetDuration(3 * time.Minute)
+	p.Set(10*time.Second, at(0))
+	if got := p.At(at(5 * time.Second)); got != 10*time.Second {
+		t.Errorf("stopped At() = %v, want it frozen at 10s", got)
+	}
+
+	p.SetRunning(true, at(0))
+	if got := p.At(at(5 * time.Second)); got != 15*time.Second {
+		t.Errorf("running At(+5s) = %v, want 15s", got)
+	}
+	// Starting again does not reset the clock.
+	p.SetRunning(true, at(4*time.Second))
+	if got := p.At(at(5 * time.Second)); got != 15*time.Second {
+		t.Errorf("At(+5s) after a repeated start = %v, want 15s", got)
+	}
+
+	p.SetRunning(false, at(20*time.Second))
+	if got := p.At(at(time.Hour)); got != 30*time.Second {
+		t.Errorf("paused At() = %v, want the 30s reached when paused", got)
+	}
+	p.SetRunning(true, at(time.Hour))
+	if got := p.At(at(time.Hour + time.Second)); got != 31*time.Second {
+		t.Errorf("resumed At(+1s) = %v, want 31s", got)
+	}
+
+	if got := p.At(at(2 * time.Hour)); got != 3*time.Minute {
+		t.Errorf("At() past the end = %v, want the 3m duration", got)
+	}
+	p.Set(-time.Second, at(0))
+	p.SetRunning(false, at(0))
+	if got := p.At(at(0)); got != 0 {
+		t.Errorf("At() of a negative report = %v, want 0", got)
+	}
+}
+
+func TestPositionWithoutDuration(t *testing.T) {
+	t0 := time.Now()
+	var p Position
+	p.Set(time.Hour, t0)
+	p.SetRunning(true, t0)
+	if got := p.At(t0.Add(time.Minute)); got != time.Hour+time.Minute {
+		t.Errorf("At() = %v, want no clamping without a duration", got)
+	}
+}
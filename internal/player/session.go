@@ -0,0 +1,215 @@
+package player
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// SessionEntry is one line of a recorded daemon session: either a raw
+// WebSocket event or a REST /status snapshot, stamped with the time since the
+// recording started.
+type SessionEntry struct {
+	Offset int64           `json:"t"` // ms since the start of the recording
+	Time   time.Time       `json:"time"`
+	Event  json.RawMessage `json:"event,omitempty"`
+	Status *Status         `json:"status,omitempty"`
+}
+
+// Recorder appends daemon events and status snapshots to a session file as
+// JSON lines. It is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	f     *os.File
+	w     *bufio.Writer
+	start time.Time
+	done  chan struct{}
+}
+
+// NewRecorder creates (or truncates) the session file at path.
+func NewRecorder(path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating session file: %w", err)
+	}
+	return &Recorder{
+		f:     f,
+		w:     bufio.NewWriter(f),
+		start: time.Now(),
+		done:  make(chan struct{}),
+	}, nil
+}
+
+// Event records a raw /events message exactly as received.
+func (r *Recorder) Event(raw []byte) error {
+	return r.write(SessionEntry{Event: append(json.RawMessage(nil), raw...)})
+}
+
+// Status records a /status snapshot.
+func (r *Recorder) Status(s *Status) error {
+	return r.write(SessionEntry{Status: s})
+}
+
+// PollStatus records a status snapshot from pc every interval until the
+// recorder is closed. Failed polls are skipped.
+func (r *Recorder) PollStatus(pc *Client, interval time.Duration) {
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-r.done:
+				return
+			case <-t.C:
+				if s, err := pc.Status(); err == nil {
+					_ = r.Status(s)
+				}
+			}
+		}
+	}()
+}
+
+func (r *Recorder) write(e SessionEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	e.Offset = now.Sub(r.start).Milliseconds()
+	e.Time = now
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	r.w.Write(data)
+	r.w.WriteByte('\n')
+	// Flush per entry so a crash still leaves a usable recording.
+	return r.w.Flush()
+}
+
+// Close stops status polling and closes the session file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	select {
+	case <-r.done:
+		return nil
+	default:
+		close(r.done)
+	}
+	r.w.Flush()
+	return r.f.Close()
+}
+
+// LoadSession reads a session file written by Recorder.
+func LoadSession(path string) ([]SessionEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []SessionEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e SessionEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Replayer feeds a recorded session to an event consumer, reproducing the
+// original timing. Status snapshots are delivered as synthetic events of type
+// "status" whose data is the Status JSON, so consumers can resynchronise the
+// same way they would from a REST poll.
+type Replayer struct {
+	entries []SessionEntry
+	speed   float64
+	done    chan struct{}
+	Ch      chan Event
+}
+
+// NewReplayer creates a Replayer. speed scales playback (2 replays twice as
+// fast); values <= 0 deliver every entry immediately.
+func NewReplayer(entries []SessionEntry, speed float64) *Replayer {
+	return &Replayer{
+		entries: entries,
+		speed:   speed,
+		done:    make(chan struct{}),
+		Ch:      make(chan Event, 32),
+	}
+}
+
+// InitialStatus returns the first status snapshot in the session, or nil.
+func (r *Replayer) InitialStatus() *Status {
+	for _, e := range r.entries {
+		if e.Status != nil {
+			return e.Status
+		}
+	}
+	return nil
+}
+
+// Start begins delivering entries in a background goroutine. Unlike a live
+// EventHandler the channel stays open after the last entry, so a UI keeps
+// showing the final state instead of exiting; it is closed by Close.
+func (r *Replayer) Start() {
+	go func() {
+		defer close(r.Ch)
+		start := time.Now()
+		for _, e := range r.entries {
+			if r.speed > 0 {
+				at := time.Duration(float64(e.Offset)/r.speed) * time.Millisecond
+				select {
+				case <-r.done:
+					return
+				case <-time.After(time.Until(start.Add(at))):
+				}
+			}
+
+			ev, ok := e.toEvent()
+			if !ok {
+				continue
+			}
+			select {
+			case <-r.done:
+				return
+			case r.Ch <- ev:
+			}
+		}
+		<-r.done
+	}()
+}
+
+// Close stops the replay and closes the event channel.
+func (r *Replayer) Close() {
+	select {
+	case <-r.done:
+	default:
+		close(r.done)
+	}
+}
+
+// toEvent converts an entry to the Event a consumer would have received.
+func (e SessionEntry) toEvent() (Event, bool) {
+	if e.Status != nil {
+		data, err := json.Marshal(e.Status)
+		if err != nil {
+			return Event{}, false
+		}
+		return Event{Type: "status", Data: data}, true
+	}
+	var ev Event
+	if len(e.Event) == 0 || json.Unmarshal(e.Event, &ev) != nil {
+		return Event{}, false
+	}
+	return ev, true
+}
@@ -4,6 +4,12 @@ import "encoding/json"
 
 // Status represents the full playback status returned by GET /status.
 type Status struct {
+	Username       string `json:"username"`
+	DeviceID       string `json:"device_id"`
+	DeviceType     string `json:"device_type"`
+	DeviceName     string `json:"device_name"`
+	PlayOrigin     string `json:"play_origin"`
+	ContextURI     string `json:"context_uri"`
 	Stopped        bool   `json:"stopped"`
 	Paused         bool   `json:"paused"`
 	Buffering      bool   `json:"buffering"`
@@ -22,9 +28,19 @@ type Track struct {
 	ArtistNames []string `json:"artist_names"`
 	AlbumName   string   `json:"album_name"`
 	AlbumCover  string   `json:"album_cover_url"`
+	ReleaseDate string   `json:"release_date"`
+	TrackNumber int      `json:"track_number"`
+	DiscNumber  int      `json:"disc_number"`
+	Position    int      `json:"position"` // milliseconds
 	Duration    int      `json:"duration"` // milliseconds
 }
 
+// Volume is the response of GET /player/volume.
+type Volume struct {
+	Value int `json:"value"`
+	Max   int `json:"max"`
+}
+
 // Event is a WebSocket event sent by go-librespot on /events.
 type Event struct {
 	Type string          `json:"type"`
@@ -33,19 +49,35 @@ type Event struct {
 
 // EventMetadata is the data payload for "metadata" events.
 type EventMetadata struct {
+	ContextURI  string   `json:"context_uri"`
+	PlayOrigin  string   `json:"play_origin"`
 	URI         string   `json:"uri"`
 	Name        string   `json:"name"`
 	ArtistNames []string `json:"artist_names"`
 	AlbumName   string   `json:"album_name"`
 	AlbumCover  string   `json:"album_cover_url"`
+	ReleaseDate string   `json:"release_date"`
+	TrackNumber int      `json:"track_number"`
+	DiscNumber  int      `json:"disc_number"`
 	Duration    int      `json:"duration"` // ms
 	Position    int      `json:"position"` // ms
 }
 
+// EventPlayback is the data payload for "playing", "paused", "not_playing"
+// and "will_play" events.
+type EventPlayback struct {
+	ContextURI string `json:"context_uri"`
+	URI        string `json:"uri"`
+	PlayOrigin string `json:"play_origin"`
+}
+
 // EventSeek is the data payload for "seek" events.
 type EventSeek struct {
-	Position int `json:"position"` // ms
-	Duration int `json:"duration"` // ms
+	ContextURI string `json:"context_uri"`
+	URI        string `json:"uri"`
+	Position   int    `json:"position"` // ms
+	Duration   int    `json:"duration"` // ms
+	PlayOrigin string `json:"play_origin"`
 }
 
 // EventVolume is the data payload for "volume" events.
@@ -0,0 +1,293 @@
+// Package playlistfile writes playlists to files (JSON, CSV, M3U and XSPF)
+// and reads Spotify track and episode references back from them, for backing
+// up, sharing and versioning playlists. Exports contain nothing that changes
+// between runs, such as the export time, so they diff cleanly.
+package playlistfile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"cli_spotify/internal/webapi"
+)
+
+// Formats lists the supported export formats.
+var Formats = []string{"json", "csv", "m3u", "xspf"}
+
+// FormatFor returns the format matching a file name's extension, or "" if
+// there is none.
+func FormatFor(path string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	if ext == "m3u8" {
+		return "m3u"
+	}
+	for _, f := range Formats {
+		if f == ext {
+			return f
+		}
+	}
+	return ""
+}
+
+// Write exports p and its items to w in the given format. M3U has no place
+// for when items were added; the other formats keep everything.
+func Write(w io.Writer, format string, p *webapi.Playlist, items []webapi.PlaylistItem) error {
+	switch format {
+	case "json":
+		return writeJSON(w, p, items)
+	case "csv":
+		return writeCSV(w, items)
+	case "m3u":
+		return writeM3U(w, p, items)
+	case "xspf":
+		return writeXSPF(w, p, items)
+	}
+	return fmt.Errorf("unknown format %q (want %s)", format, strings.Join(Formats, ", "))
+}
+
+// jsonPlaylist is the JSON export.
+type jsonPlaylist struct {
+	Name        string      `json:"name"`
+	URI         string      `json:"uri,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Owner       string      `json:"owner,omitempty"`
+	Tracks      []jsonTrack `json:"tracks"`
+}
+
+type jsonTrack struct {
+	URI        string    `json:"uri"`
+	Name       string    `json:"name"`
+	Artists    []string  `json:"artists"`
+	Album      string    `json:"album,omitempty"`
+	DurationMs int       `json:"duration_ms"`
+	AddedAt    time.Time `json:"added_at,omitzero"`
+}
+
+func writeJSON(w io.Writer, p *webapi.Playlist, items []webapi.PlaylistItem) error {
+	out := jsonPlaylist{
+		Name:        p.Name,
+		URI:         p.URI,
+		Description: p.Description,
+		Owner:       p.Owner.ID,
+		Tracks:      make([]jsonTrack, len(items)),
+	}
+	for i, it := range items {
+		t := it.Track
+		artists := make([]string, len(t.Artists))
+		for j, a := range t.Artists {
+			artists[j] = a.Name
+		}
+		if len(artists) == 0 && t.Show != nil {
+			artists = []string{t.Show.Name}
+		}
+		out.Tracks[i] = jsonTrack{
+			URI:        t.LibraryURI(),
+			Name:       t.Name,
+			Artists:    artists,
+			Album:      t.Album.Name,
+			DurationMs: t.Duration,
+			AddedAt:    it.AddedAt,
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(out)
+}
+
+func writeCSV(w io.Writer, items []webapi.PlaylistItem) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"uri", "name", "artists", "album", "duration_ms", "added_at"})
+	for _, it := range items {
+		t := it.Track
+		added := ""
+		if !it.AddedAt.IsZero() {
+			added = it.AddedAt.UTC().Format(time.RFC3339)
+		}
+		cw.Write([]string{t.LibraryURI(), t.Name, t.ArtistNames(), t.Album.Name, strconv.Itoa(t.Duration), added})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeM3U writes an extended M3U playlist whose locations are
+// open.spotify.com links.
+func writeM3U(w io.Writer, p *webapi.Playlist, items []webapi.PlaylistItem) error {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#PLAYLIST:" + oneLine(p.Name) + "\n")
+	for _, it := range items {
+		t := it.Track
+		fmt.Fprintf(&b, "#EXTINF:%d,%s - %s\n", (t.Duration+500)/1000, oneLine(t.ArtistNames()), oneLine(t.Name))
+		b.WriteString(Link(t.LibraryURI()) + "\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// xspfAddedAt is the meta rel under which XSPF exports record when an item
+// was added; XSPF has no field of its own for it.
+const xspfAddedAt = "https://open.spotify.com/added_at"
+
+type xspfPlaylist struct {
+	XMLName    xml.Name    `xml:"playlist"`
+	Version    string      `xml:"version,attr"`
+	Namespace  string      `xml:"xmlns,attr"`
+	Title      string      `xml:"title,omitempty"`
+	Creator    string      `xml:"creator,omitempty"`
+	Annotation string      `xml:"annotation,omitempty"`
+	Identifier string      `xml:"identifier,omitempty"`
+	Tracks     []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location   string     `xml:"location"`
+	Identifier string     `xml:"identifier"`
+	Title      string     `xml:"title,omitempty"`
+	Creator    string     `xml:"creator,omitempty"`
+	Album      string     `xml:"album,omitempty"`
+	Duration   int        `xml:"duration,omitempty"` // ms
+	Meta       []xspfMeta `xml:"meta,omitempty"`
+}
+
+type xspfMeta struct {
+	Rel   string `xml:"rel,attr"`
+	Value string `xml:",chardata"`
+}
+
+func writeXSPF(w io.Writer, p *webapi.Playlist, items []webapi.PlaylistItem) error {
+	out := xspfPlaylist{
+		Version:    "1",
+		Namespace:  "http://xspf.org/ns/0/",
+		Title:      p.Name,
+		Creator:    p.Owner.DisplayName,
+		Annotation: p.Description,
+		Identifier: p.URI,
+		Tracks:     make([]xspfTrack, len(items)),
+	}
+	for i, it := range items {
+		t := it.Track
+		x := xspfTrack{
+			Location:   Link(t.LibraryURI()),
+			Identifier: t.LibraryURI(),
+			Title:      t.Name,
+			Creator:    t.ArtistNames(),
+			Album:      t.Album.Name,
+			Duration:   t.Duration,
+		}
+		if !it.AddedAt.IsZero() {
+			x.Meta = []xspfMeta{{Rel: xspfAddedAt, Value: it.AddedAt.UTC().Format(time.RFC3339)}}
+		}
+		out.Tracks[i] = x
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Read extracts the track and episode URIs from a playlist file, in order
+// and keeping duplicates, along with the playlist name if the file records
+// one. It accepts this package's exports as well as any text with one
+// Spotify URI or open.spotify.com link per line.
+func Read(r io.Reader) (name string, uris []string, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", nil, err
+	}
+	text := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(text, "{"):
+		var p jsonPlaylist
+		if err := json.Unmarshal(data, &p); err != nil {
+			return "", nil, fmt.Errorf("reading JSON playlist: %w", err)
+		}
+		for _, t := range p.Tracks {
+			if uri, ok := parseItem(t.URI); ok {
+				uris = append(uris, uri)
+			}
+		}
+		return p.Name, uris, nil
+	case strings.HasPrefix(text, "<?xml") || strings.HasPrefix(text, "<playlist"):
+		var p xspfPlaylist
+		if err := xml.Unmarshal(data, &p); err != nil {
+			return "", nil, fmt.Errorf("reading XSPF playlist: %w", err)
+		}
+		for _, t := range p.Tracks {
+			uri, ok := parseItem(t.Identifier)
+			if !ok {
+				uri, ok = parseItem(t.Location)
+			}
+			if ok {
+				uris = append(uris, uri)
+			}
+		}
+		return p.Title, uris, nil
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, "#PLAYLIST:"); ok {
+			name = strings.TrimSpace(rest)
+			continue
+		}
+		if uri, ok := parseItem(line); ok {
+			uris = append(uris, uri)
+		}
+	}
+	return name, uris, nil
+}
+
+var (
+	uriPattern  = regexp.MustCompile(`spotify:(track|episode|playlist):([A-Za-z0-9]{22})`)
+	linkPattern = regexp.MustCompile(`open\.spotify\.com/(?:intl-[a-zA-Z-]+/)?(track|episode|playlist)/([A-Za-z0-9]{22})`)
+)
+
+// ParseURI finds the first Spotify track, episode or playlist reference in s,
+// as a URI (spotify:track:ID) or an open.spotify.com link, and returns it as
+// a URI.
+func ParseURI(s string) (string, bool) {
+	m := uriPattern.FindStringSubmatchIndex(s)
+	if l := linkPattern.FindStringSubmatchIndex(s); l != nil && (m == nil || l[0] < m[0]) {
+		m = l
+	}
+	if m == nil {
+		return "", false
+	}
+	return "spotify:" + s[m[2]:m[3]] + ":" + s[m[4]:m[5]], true
+}
+
+// parseItem is ParseURI restricted to tracks and episodes.
+func parseItem(s string) (string, bool) {
+	uri, ok := ParseURI(s)
+	if !ok || strings.HasPrefix(uri, "spotify:playlist:") {
+		return "", false
+	}
+	return uri, true
+}
+
+// Link returns the open.spotify.com link for a Spotify URI.
+func Link(uri string) string {
+	parts := strings.Split(uri, ":")
+	if len(parts) != 3 {
+		return uri
+	}
+	return "https://open.spotify.com/" + parts[1] + "/" + parts[2]
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,196 @@
+package playlistfile
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"cli_spotify/internal/webapi"
+)
+
+const (
+	trackA  = "spotify:track:4uLU6hMCjMI75M1A2tKUQC"
+	trackB  = "spotify:track:7GhIk7Il098yCjg4BQjzvb"
+	episode = "spotify:episode:512ojhOuo1ktJprKbVcKyQ"
+)
+
+func testPlaylist() (*webapi.Playlist, []webapi.PlaylistItem) {
+	p := &webapi.Playlist{
+		URI:         "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M",
+		Name:        "Road  Trip\nMix",
+		Description: "For the car",
+		Owner:       webapi.Owner{ID: "tester", DisplayName: "Test User"},
+	}
+	added := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	items := []webapi.PlaylistItem{
+		{Track: webapi.Track{
+			URI: trackA, Name: "Never Gonna Give You Up", Duration: 213573,
+			Artists: []webapi.Artist{{Name: "Rick Astley"}}, Album: webapi.Album{Name: "Whenever You Need Somebody"},
+		}, AddedAt: added},
+		{Track: webapi.Track{
+			URI: episode, Name: "Episode, \"One\"", Duration: 3600000,
+			Show: &webapi.Show{Name: "A Podcast"},
+		}},
+		// A relinked track is exported under the URI saved in the playlist.
+		{Track: webapi.Track{
+			URI: "spotify:track:0000000000000000000000", Name: "Relinked",
+			Artists:    []webapi.Artist{{Name: "One"}, {Name: "Two"}},
+			LinkedFrom: &webapi.LinkedTrack{URI: trackB},
+		}, AddedAt: added},
+		{Track: webapi.Track{URI: trackA, Name: "Never Gonna Give You Up"}, AddedAt: added},
+	}
+	return p, items
+}
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	p, items := testPlaylist()
+	want := []string{trackA, episode, trackB, trackA}
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, format, p, items); err != nil {
+				t.Fatal(err)
+			}
+			name, uris, err := Read(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(uris, want) {
+				t.Errorf("uris = %q, want %q", uris, want)
+			}
+			wantName := p.Name
+			switch format {
+			case "csv":
+				wantName = "" // CSV has no place for it
+			case "m3u":
+				wantName = "Road Trip Mix" // one line
+			}
+			if name != wantName {
+				t.Errorf("name = %q, want %q", name, wantName)
+			}
+		})
+	}
+}
+
+func TestWriteIsStable(t *testing.T) {
+	p, items := testPlaylist()
+	for _, format := range Formats {
+		var a, b bytes.Buffer
+		if err := Write(&a, format, p, items); err != nil {
+			t.Fatal(err)
+		}
+		Write(&b, format, p, items)
+		if a.String() != b.String() {
+			t.Errorf("%s: two exports of the same playlist differ", format)
+		}
+	}
+}
+
+func TestWriteM3U(t *testing.T) {
+	p, items := testPlaylist()
+	var buf bytes.Buffer
+	if err := Write(&buf, "m3u", p, items[:2]); err != nil {
+		t.Fatal(err)
+	}
+	want := `#EXTM3U
+#PLAYLIST:Road Trip Mix
+#EXTINF:214,Rick Astley - Never Gonna Give You Up
+https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC
+#EXTINF:3600,A Podcast - Episode, "One"
+https://open.spotify.com/episode/512ojhOuo1ktJprKbVcKyQ
+`
+	if buf.String() != want {
+		t.Errorf("m3u =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteKeepsAddedAt(t *testing.T) {
+	p, items := testPlaylist()
+	for _, format := range []string{"json", "csv", "xspf"} {
+		var buf bytes.Buffer
+		if err := Write(&buf, format, p, items); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "2024-05-01T12:00:00Z") {
+			t.Errorf("%s export lacks when items were added:\n%s", format, buf.String())
+		}
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	p, items := testPlaylist()
+	if err := Write(&bytes.Buffer{}, "pls", p, items); err == nil {
+		t.Error("Write(pls) succeeded")
+	}
+}
+
+func TestReadPlainText(t *testing.T) {
+	text := `# favourites
+https://open.spotify.com/intl-de/track/4uLU6hMCjMI75M1A2tKUQC?si=abc
+not a link
+spotify:episode:512ojhOuo1ktJprKbVcKyQ
+https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M
+`
+	name, uris, err := Read(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{trackA, episode}; !slices.Equal(uris, want) {
+		t.Errorf("uris = %q, want %q (playlists are not items)", uris, want)
+	}
+	if name != "" {
+		t.Errorf("name = %q, want none", name)
+	}
+}
+
+func TestReadMalformed(t *testing.T) {
+	for _, text := range []string{`{"name": `, `<playlist><trackList>`} {
+		if _, _, err := Read(strings.NewReader(text)); err == nil {
+			t.Errorf("Read(%q) succeeded", text)
+		}
+	}
+}
+
+func TestParseURI(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"spotify:track:4uLU6hMCjMI75M1A2tKUQC", trackA},
+		{"https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC", trackA},
+		{"https://open.spotify.com/intl-pt-BR/track/4uLU6hMCjMI75M1A2tKUQC?si=x", trackA},
+		{"open.spotify.com/episode/512ojhOuo1ktJprKbVcKyQ", episode},
+		{"see https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M", "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M"},
+		{"https://open.spotify.com/track/7GhIk7Il098yCjg4BQjzvb then spotify:track:4uLU6hMCjMI75M1A2tKUQC", trackB},
+		{"spotify:artist:0gxyHStUsqpMadRV0Di1Qt", ""},
+		{"spotify:track:tooShort", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, ok := ParseURI(tt.in)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("ParseURI(%q) = %q, %v; want %q", tt.in, got, ok, tt.want)
+		}
+	}
+}
+
+func TestLink(t *testing.T) {
+	if got := Link(trackA); got != "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC" {
+		t.Errorf("Link(track) = %q", got)
+	}
+	if got := Link("spotify:local:a:b:c:1"); got != "spotify:local:a:b:c:1" {
+		t.Errorf("Link(local file) = %q, want it unchanged", got)
+	}
+}
+
+func TestFormatFor(t *testing.T) {
+	for path, want := range map[string]string{
+		"mix.json": "json", "Mix.XSPF": "xspf", "mix.m3u8": "m3u", "mix.m3u": "m3u", "mix.csv": "csv", "mix.txt": "", "mix": "",
+	} {
+		if got := FormatFor(path); got != want {
+			t.Errorf("FormatFor(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
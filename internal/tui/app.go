@@ -4,10 +4,12 @@
 package tui
 
 import (
+	"fmt"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"cli_spotify/internal/library"
 	"cli_spotify/internal/player"
 	"cli_spotify/internal/webapi"
 )
@@ -20,51 +22,84 @@ const (
 	viewSearch
 	viewLibrary
 	viewPlaylist
+	viewArtist
+	viewPicker
+	viewHistory
+	viewDevices
+	viewQueue
+	viewShow
 )
 
 // playback holds the live state of the current track, updated from WebSocket
-// events and advanced by the per-second tick.
+// events and periodically reconciled with the REST status.
 type playback struct {
+	uri       string
 	trackName string
 	artists   string
 	album     string
-	duration  time.Duration
-	progress  time.Duration
+	pos       player.Position
 	isPlaying bool
+	buffering bool
 	shuffle   bool
 	repeat    string // "off", "context", "track"
 	volume    int
 	stopped   bool
+
+	contextURI string // what is playing from: album, playlist, ...
+	deviceID   string // this CLI's Connect device, from the daemon status
+	deviceName string
 }
 
 // Model is the root Bubble Tea model.
 type Model struct {
 	pc     *player.Client
 	web    *webapi.Client
-	events *player.EventHandler
+	events <-chan player.Event
+	sync   *library.Syncer // nil without a library mirror
 
 	view     view
 	pb       playback
 	search   searchState
 	library  libraryState
 	playlist playlistState
+	artist   artistState
+	history  historyState
+	devices  devicesState
+	queue    queueState
+	show     showState
+	picker   pickerState
+	prompt   promptState
+	undo     []removal // playlist removals that can still be undone
+	userID   string
+	liked    map[string]bool // Liked Songs membership by track URI, as far as known
 	width    int
 	height   int
+	notice   string // last control error or retry notice
+	syncNote string // latest library sync progress
+
+	// lastEvent is when the most recent daemon event was applied. A status
+	// poll sent before it is stale and is discarded.
+	lastEvent time.Time
 }
 
 // New creates the root model, seeding playback state from an initial status
-// snapshot (may be nil).
-func New(pc *player.Client, web *webapi.Client, events *player.EventHandler, status *player.Status) Model {
+// snapshot (may be nil). events is the daemon event stream — live from an
+// EventHandler or recorded from a Replayer. pc and web are nil when replaying
+// a session, which leaves the UI read-only. sync, if not nil, keeps the
+// library mirror the library view reads from.
+func New(pc *player.Client, web *webapi.Client, events <-chan player.Event, status *player.Status, sync *library.Syncer) Model {
 	m := Model{
 		pc:     pc,
 		web:    web,
 		events: events,
+		sync:   sync,
 		view:   viewNowPlaying,
 		pb:     playback{repeat: "off"},
 		search: newSearchState(),
+		liked:  make(map[string]bool),
 	}
 	if status != nil {
-		m.applyStatus(status)
+		m.applyStatus(status, time.Now())
 	}
 	return m
 }
@@ -77,7 +112,17 @@ func Run(m Model) error {
 }
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(listenEvents(m.events), tickCmd())
+	cmds := []tea.Cmd{listenEvents(m.events), tickCmd()}
+	if m.pc != nil {
+		cmds = append(cmds, reconcileCmd())
+	}
+	if m.web != nil {
+		cmds = append(cmds, listenRetries(m.web.Retries()), loadUser(m.web), m.refreshSaved(m.pb.uri))
+	}
+	if m.sync != nil {
+		cmds = append(cmds, listenSync(m.sync.Progress()))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -87,70 +132,163 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tickMsg:
-		if m.pb.isPlaying && !m.pb.stopped {
-			m.pb.progress += time.Second
-			if m.pb.progress > m.pb.duration {
-				m.pb.progress = m.pb.duration
-			}
-		}
+		// The position is extrapolated at render time; the tick only redraws.
 		return m, tickCmd()
 
+	case reconcileMsg:
+		return m, fetchStatus(m.pc)
+
+	case statusMsg:
+		if msg.err == nil && !msg.sent.Before(m.lastEvent) {
+			m.applyStatus(msg.status, msg.at)
+		}
+		if msg.resync {
+			return m, nil // the periodic poll is scheduled separately
+		}
+		return m, reconcileCmd()
+
 	case playerEventMsg:
 		if !msg.ok {
 			return m, tea.Quit // event stream closed
 		}
-		m.applyEvent(msg.ev)
-		return m, listenEvents(m.events)
+		now, prev := time.Now(), m.pb.uri
+		m.applyEvent(msg.ev, now)
+		m.lastEvent = now
+		m, advance := m.advanceQueue()
+		// Only a new track needs a lookup: repeating it on every event would
+		// resend a failed one (and its notice) for as long as it plays.
+		var saved tea.Cmd
+		if m.pb.uri != prev {
+			saved = m.refreshSaved(m.pb.uri)
+		}
+		return m, tea.Batch(listenEvents(m.events), saved, advance)
 
 	case searchResultsMsg:
+		return m.applySearchResults(msg)
+
+	case searchMoreMsg:
+		return m.applySearchMore(msg)
+
+	case artistMsg:
+		return m.applyArtist(msg)
+
+	case savedMsg:
+		return m.applySaved(msg)
+
+	case likeMsg:
+		return m.applyLike(msg)
+
+	case userMsg:
+		m.userID = msg.user.ID
+		return m, nil
+
+	case editablePlaylistsMsg:
+		return m.applyEditablePlaylists(msg)
+
+	case playlistEditMsg:
+		return m.applyPlaylistEdit(msg)
+
+	case playlistCreatedMsg:
 		if msg.err != nil {
-			m.search.status = "Search failed: " + msg.err.Error()
+			m.notice = "Creating playlist failed: " + msg.err.Error()
 			return m, nil
 		}
-		m.search.results = msg.tracks
-		m.search.cursor = 0
-		if len(msg.tracks) == 0 {
-			m.search.status = "No results."
-		} else {
-			m.search.status = ""
+		m.notice = "Created playlist " + msg.playlist.Name
+		if msg.added != "" {
+			m.notice += " with " + msg.added
+		}
+		// Reload the library on the next visit so the playlist shows up.
+		m.library = libraryState{}
+		m.syncLibrary()
+		if m.view == viewLibrary {
+			return m.enterLibrary()
 		}
 		return m, nil
 
-	case playResultMsg:
+	case trackArtistMsg:
 		if msg.err != nil {
-			m.search.status = "Play failed: " + msg.err.Error()
-			m.playlist.status = "Error: " + msg.err.Error()
+			m.notice = "Go to artist failed: " + msg.err.Error()
+			return m, nil
 		}
-		return m, nil
+		return m.openArtist(msg.artist.URI, msg.artist.Name, viewNowPlaying)
 
-	case playlistListMsg:
-		m.library.loaded = true
-		if msg.err != nil {
-			m.library.status = "Failed: " + msg.err.Error()
+	case controlMsg:
+		if msg.err == nil {
+			m.notice = ""
+			return m, nil
+		}
+		if msg.rollback != nil {
+			msg.rollback(&m.pb)
+			m.syncClock(time.Now())
+		}
+		m.notice = msg.action + " failed: " + msg.err.Error()
+		// The request may have partly applied; ask the daemon what happened.
+		return m, resyncStatus(m.pc)
+
+	case retryMsg:
+		secs := int(msg.ev.Wait.Round(time.Second) / time.Second)
+		if msg.ev.RateLimited {
+			m.notice = fmt.Sprintf("Rate limited by Spotify, retrying in %ds", secs)
+		} else if msg.ev.Status != 0 {
+			m.notice = fmt.Sprintf("Spotify returned %d, retrying in %ds", msg.ev.Status, secs)
 		} else {
-			m.library.albums = msg.albums
-			m.library.playlists = msg.playlists
-			m.library.status = ""
+			m.notice = fmt.Sprintf("Network error, retrying in %ds", secs)
+		}
+		return m, tea.Batch(listenRetries(m.web.Retries()), clearNoticeAfter(msg.ev.Wait+time.Second, m.notice))
+
+	case syncMsg:
+		return m.applySync(msg)
+
+	case clearNoticeMsg:
+		if m.notice == msg.text {
+			m.notice = ""
 		}
 		return m, nil
 
-	case playlistTracksMsg:
+	case playResultMsg:
 		if msg.err != nil {
-			// 403 on non-owned playlists in dev mode — play the playlist directly
-			// as a context URI instead of showing individual tracks.
-			if m.playlist.uri != "" {
-				m.view = viewNowPlaying
-				return m, playTrack(m.pc, m.playlist.uri, "", m.playlist.name)
-			}
+			m.search.status = "Play failed: " + msg.err.Error()
 			m.playlist.status = "Error: " + msg.err.Error()
-		} else {
-			m.playlist.tracks = msg.tracks
-			m.playlist.cursor = 0
-			m.playlist.status = ""
 		}
 		return m, nil
 
+	case albumsPageMsg:
+		return m.applyAlbumsPage(msg)
+
+	case playlistsPageMsg:
+		return m.applyPlaylistsPage(msg)
+
+	case showsPageMsg:
+		return m.applyShowsPage(msg)
+
+	case episodesPageMsg:
+		return m.applyEpisodesPage(msg)
+
+	case playlistTracksMsg:
+		return m.applyTracksPage(msg)
+
+	case recentMsg:
+		return m.applyRecent(msg)
+
+	case topMsg:
+		return m.applyTop(msg)
+
+	case devicesMsg:
+		return m.applyDevices(msg)
+
+	case transferMsg:
+		return m.applyTransfer(msg)
+
+	case queueMsg:
+		return m.applyQueue(msg)
+
+	case queuedMsg:
+		return m.applyQueued(msg)
+
 	case tea.KeyMsg:
+		if m.prompt.active {
+			return m.handlePromptKey(msg)
+		}
 		switch m.view {
 		case viewSearch:
 			return m.handleSearchKey(msg)
@@ -158,6 +296,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleLibraryKey(msg)
 		case viewPlaylist:
 			return m.handlePlaylistKey(msg)
+		case viewArtist:
+			return m.handleArtistKey(msg)
+		case viewPicker:
+			return m.handlePickerKey(msg)
+		case viewHistory:
+			return m.handleHistoryKey(msg)
+		case viewDevices:
+			return m.handleDevicesKey(msg)
+		case viewQueue:
+			return m.handleQueueKey(msg)
+		case viewShow:
+			return m.handleShowKey(msg)
 		default:
 			return m.handleKey(msg)
 		}
@@ -167,20 +317,53 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) View() string {
+	var s string
 	switch m.view {
 	case viewSearch:
-		return m.searchView()
+		s = m.searchView()
 	case viewLibrary:
-		return m.libraryView()
+		s = m.libraryView()
 	case viewPlaylist:
-		return m.playlistView()
+		s = m.playlistView()
+	case viewArtist:
+		s = m.artistView()
+	case viewPicker:
+		s = m.pickerView()
+	case viewHistory:
+		s = m.historyView()
+	case viewDevices:
+		s = m.devicesView()
+	case viewQueue:
+		s = m.queueView()
+	case viewShow:
+		s = m.showView()
 	default:
-		return m.nowPlayingView()
+		// The now-playing screen renders the notice in its status area.
+		s = m.nowPlayingView()
+		if m.prompt.active {
+			s += "\n" + m.prompt.input.View() + "\n"
+		}
+		return s
+	}
+	if m.prompt.active {
+		s += "\n" + m.prompt.input.View() + "\n"
 	}
+	if m.notice != "" {
+		s += "\n" + yellowStyle.Render("  [!] "+truncate(m.notice, 70)) + "\n"
+	}
+	return s
 }
 
 // handleKey processes a key press in the now-playing view.
 func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.pc == nil || m.web == nil {
+		// Session replay: there is no daemon or Web API to drive.
+		if k := msg.String(); k == "q" || k == "ctrl+c" {
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+
 	switch msg.String() {
 	case "q", "ctrl+c":
 		return m, tea.Quit
@@ -191,49 +374,128 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "p":
 		return m.enterLibrary()
 
+	case "H":
+		return m.enterHistory()
+
+	case "d":
+		return m.enterDevices()
+
+	case "u":
+		return m.enterQueue()
+
+	case "a":
+		if m.pb.uri == "" || m.pb.stopped {
+			return m, nil
+		}
+		return m, loadTrackArtist(m.web, m.pb.uri)
+
+	case "+":
+		if m.pb.stopped {
+			return m, nil
+		}
+		return m.openPicker(m.pb.uri, m.pb.trackName)
+
+	case "f":
+		return m.toggleLiked(m.pb.uri)
+
 	case " ":
-		_ = m.pc.PlayPause()
-		m.pb.isPlaying = !m.pb.isPlaying
+		was := m.pb.isPlaying
+		m.pb.isPlaying = !was
+		m.syncClock(time.Now())
+		call := m.pc.Resume
+		if was {
+			call = m.pc.Pause
+		}
+		return m, control("Play/pause", call, func(pb *playback) {
+			if pb.isPlaying == !was {
+				pb.isPlaying = was
+			}
+		})
 
 	case "right", "l":
-		_ = m.pc.Next()
+		if m.pb.isEpisode() {
+			return m, control("Skip forward", func() error { return m.pc.SeekRelative(30000) }, nil)
+		}
+		return m, control("Next", m.pc.Next, nil)
 
 	case "left", "h":
-		_ = m.pc.Prev()
+		if m.pb.isEpisode() {
+			return m, control("Skip back", func() error { return m.pc.SeekRelative(-15000) }, nil)
+		}
+		return m, control("Previous", m.pc.Prev, nil)
+
+	case ">", ".":
+		return m, control("Next", m.pc.Next, nil)
+
+	case "<", ",":
+		return m, control("Previous", m.pc.Prev, nil)
 
 	case "up", "k":
-		_ = m.pc.SetVolumeRelative(5)
-		m.pb.volume = min(100, m.pb.volume+5)
+		return m.setVolume(m.pb.volume + 5)
 
 	case "down", "j":
-		_ = m.pc.SetVolumeRelative(-5)
-		m.pb.volume = max(0, m.pb.volume-5)
+		return m.setVolume(m.pb.volume - 5)
 
 	case "s":
-		ns := !m.pb.shuffle
-		_ = m.pc.SetShuffle(ns)
-		m.pb.shuffle = ns
+		was := m.pb.shuffle
+		m.pb.shuffle = !was
+		return m, control("Shuffle", func() error { return m.pc.SetShuffle(!was) },
+			func(pb *playback) {
+				if pb.shuffle == !was {
+					pb.shuffle = was
+				}
+			})
 
 	case "r":
-		m.cycleRepeat()
+		return m, m.cycleRepeat()
 	}
 	return m, nil
 }
 
-// cycleRepeat cycles through: off → context → track → off.
-func (m *Model) cycleRepeat() {
-	switch m.pb.repeat {
+// setVolume optimistically shows the new absolute volume and sends it to the
+// daemon, restoring the previous value if the request fails and nothing newer
+// has replaced it since.
+func (m Model) setVolume(vol int) (Model, tea.Cmd) {
+	vol = max(0, min(100, vol))
+	was := m.pb.volume
+	m.pb.volume = vol
+	return m, control("Volume", func() error { return m.pc.SetVolume(vol) },
+		func(pb *playback) {
+			if pb.volume == vol {
+				pb.volume = was
+			}
+		})
+}
+
+// cycleRepeat cycles through: off → context → track → off. context → track
+// takes two requests; if only the first succeeds the daemon is left at off,
+// which the status resync after a failed control picks up.
+func (m *Model) cycleRepeat() tea.Cmd {
+	was := m.pb.repeat
+	pc := m.pc
+	var call func() error
+	switch was {
 	case "off":
-		_ = m.pc.SetRepeatContext(true)
 		m.pb.repeat = "context"
+		call = func() error { return pc.SetRepeatContext(true) }
 	case "context":
-		_ = m.pc.SetRepeatContext(false)
-		_ = m.pc.SetRepeatTrack(true)
 		m.pb.repeat = "track"
+		call = func() error {
+			if err := pc.SetRepeatContext(false); err != nil {
+				return err
+			}
+			return pc.SetRepeatTrack(true)
+		}
 	default:
-		_ = m.pc.SetRepeatTrack(false)
 		m.pb.repeat = "off"
+		call = func() error { return pc.SetRepeatTrack(false) }
 	}
+	want := m.pb.repeat
+	return control("Repeat", call, func(pb *playback) {
+		if pb.repeat == want {
+			pb.repeat = was
+		}
+	})
 }
 
 func min(a, b int) int {
@@ -0,0 +1,228 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"cli_spotify/internal/webapi"
+)
+
+// artistRow is one line of the artist page: a section header, a top track or
+// a release.
+type artistRow struct {
+	header string
+	track  *webapi.Track
+	album  *webapi.Album
+}
+
+// artistState holds the artist page: details, top tracks and discography.
+type artistState struct {
+	artist webapi.Artist
+	top    []webapi.Track
+	rows   []artistRow
+	cursor int
+	status string
+	back   view // screen to return to on esc
+}
+
+// openArtist switches to the artist page and loads it. back is the screen esc
+// returns to.
+func (m Model) openArtist(artistURI, name string, back view) (Model, tea.Cmd) {
+	if m.view != viewArtist {
+		m.artist.back = back
+	}
+	m.artist = artistState{
+		artist: webapi.Artist{URI: artistURI, Name: name},
+		status: "Loading...",
+		back:   m.artist.back,
+	}
+	m.view = viewArtist
+	return m, loadArtist(m.web, artistURI)
+}
+
+// openTrackArtist opens the page of a track's first artist.
+func (m Model) openTrackArtist(t *webapi.Track, back view) (Model, tea.Cmd) {
+	if t == nil || len(t.Artists) == 0 {
+		return m, nil
+	}
+	return m.openArtist(t.Artists[0].URI, t.Artists[0].Name, back)
+}
+
+// applyArtist fills the artist page once loaded.
+func (m Model) applyArtist(msg artistMsg) (Model, tea.Cmd) {
+	if msg.uri != m.artist.artist.URI {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.artist.status = "Error: " + msg.err.Error()
+		return m, nil
+	}
+	m.artist.artist = *msg.artist
+	m.artist.top = msg.top
+	m.artist.status = ""
+
+	var rows []artistRow
+	if len(msg.top) > 0 {
+		rows = append(rows, artistRow{header: "Top tracks"})
+		for i := range m.artist.top {
+			rows = append(rows, artistRow{track: &m.artist.top[i]})
+		}
+	}
+	for _, sec := range []struct {
+		title  string
+		albums []webapi.Album
+	}{
+		{"Albums", msg.discography.Albums},
+		{"Singles & EPs", msg.discography.Singles},
+		{"Compilations", msg.discography.Compilations},
+	} {
+		if len(sec.albums) == 0 {
+			continue
+		}
+		rows = append(rows, artistRow{header: sec.title})
+		for i := range sec.albums {
+			rows = append(rows, artistRow{album: &sec.albums[i]})
+		}
+	}
+	m.artist.rows = rows
+	m.artist.cursor = m.artist.step(-1, 1)
+	return m, m.refreshSaved(trackURIs(msg.top)...)
+}
+
+// step returns the next selectable row from pos in direction dir (±1), or
+// the current cursor if there is none.
+func (a *artistState) step(pos, dir int) int {
+	for i := pos + dir; i >= 0 && i < len(a.rows); i += dir {
+		if a.rows[i].header == "" {
+			return i
+		}
+	}
+	if pos < 0 {
+		return 0
+	}
+	return a.cursor
+}
+
+// handleArtistKey processes key events on the artist page.
+func (m Model) handleArtistKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.view = m.artist.back
+		return m, nil
+	case "up", "k":
+		m.artist.cursor = m.artist.step(m.artist.cursor, -1)
+	case "down", "j":
+		m.artist.cursor = m.artist.step(m.artist.cursor, 1)
+	case "+", "f", "e", "E":
+		if m.artist.cursor < len(m.artist.rows) {
+			if t := m.artist.rows[m.artist.cursor].track; t != nil {
+				switch msg.String() {
+				case "f":
+					return m.toggleLiked(t.LibraryURI())
+				case "e", "E":
+					return m.enqueue(t, msg.String() == "E")
+				}
+				return m.openPicker(t.LibraryURI(), t.Name)
+			}
+		}
+	case "enter":
+		if m.artist.cursor >= len(m.artist.rows) {
+			return m, nil
+		}
+		row := m.artist.rows[m.artist.cursor]
+		switch {
+		case row.track != nil:
+			if why := unplayable(*row.track); why != "" {
+				m.artist.status = why
+				return m, nil
+			}
+			// Play the top tracks from the highlighted one onwards, skipping
+			// unplayable ones.
+			var uris []string
+			for i, t := range m.artist.top {
+				if t.URI == row.track.URI {
+					uris = playableURIs(m.artist.top[i:])
+					break
+				}
+			}
+			m.artist.status = "Playing: " + row.track.Name
+			return m, playURIs(m.pc, uris, row.track.Name)
+		case row.album != nil:
+			a := row.album
+			m.playlist = playlistState{name: a.Name, uri: a.URI}
+			return m.openTrackList(m.web.AlbumTracksPager(a.ID), viewArtist)
+		}
+	}
+	return m, nil
+}
+
+// artistView renders the artist page.
+func (m Model) artistView() string {
+	var b strings.Builder
+	a := m.artist.artist
+	b.WriteString("\n")
+	b.WriteString(titleStyle.Render("  ♪ "+truncate(a.Name, 55)) + "\n")
+
+	var info []string
+	if a.Followers.Total > 0 {
+		info = append(info, strconv.Itoa(a.Followers.Total)+" followers")
+	}
+	if len(a.Genres) > 0 {
+		info = append(info, strings.Join(a.Genres, ", "))
+	}
+	if len(info) > 0 {
+		b.WriteString(dimStyle.Render("  "+truncate(strings.Join(info, dimSep), 70)) + "\n")
+	}
+	b.WriteString("\n")
+
+	if m.artist.status != "" {
+		b.WriteString(dimStyle.Render("  "+m.artist.status) + "\n\n")
+	}
+	if len(m.artist.rows) == 0 {
+		b.WriteString(helpStyle.Render("  [esc] back") + "\n")
+		return b.String()
+	}
+
+	visible := m.height - 10
+	if visible < 3 {
+		visible = 3
+	}
+	start := 0
+	if m.artist.cursor >= visible {
+		start = m.artist.cursor - visible + 1
+	}
+	end := min(start+visible, len(m.artist.rows))
+
+	for i := start; i < end; i++ {
+		row := m.artist.rows[i]
+		var line string
+		switch {
+		case row.header != "":
+			b.WriteString(yellowStyle.Render("  "+row.header) + "\n")
+			continue
+		case row.track != nil:
+			line = m.heart(row.track.LibraryURI()) + truncate(row.track.Name, 50) + dimSep + truncate(row.track.Album.Name, 25)
+			b.WriteString(trackRow(line, *row.track, i == m.artist.cursor))
+			continue
+		case row.album != nil:
+			year := row.album.ReleaseDate
+			if len(year) > 4 {
+				year = year[:4]
+			}
+			line = "♫  " + truncate(row.album.Name, 50) + dimSep + year
+		}
+		if i == m.artist.cursor {
+			b.WriteString(greenStyle.Render("  ▸ "+line) + "\n")
+		} else {
+			b.WriteString("    " + dimStyle.Render(line) + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  [↑↓] move  [enter] play track / open release  [f] like  [e/E] queue/next  [+] add to playlist  [esc] back") + "\n")
+	return b.String()
+}
@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"cli_spotify/internal/player"
+)
+
+// failingDaemon answers 500 to the listed paths, the given JSON to GET
+// /status and 200 to everything else.
+func failingDaemon(t *testing.T, status string, fail ...string) *player.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, f := range fail {
+			if r.URL.Path == f {
+				http.Error(w, "boom", http.StatusInternalServerError)
+				return
+			}
+		}
+		if r.URL.Path == "/status" {
+			io.WriteString(w, status)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return player.NewClientFor(player.Endpoint{BaseURL: srv.URL})
+}
+
+// run executes cmd and feeds its message back into the model, returning the
+// follow-up command.
+func run(t *testing.T, m Model, cmd tea.Cmd) (Model, tea.Cmd) {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	next, follow := m.Update(cmd())
+	return next.(Model), follow
+}
+
+func TestFailedVolumeKeepsNewerValue(t *testing.T) {
+	pc := failingDaemon(t, `{"volume": 40}`, "/player/volume")
+	m := New(pc, nil, nil, &player.Status{Volume: 40}, nil)
+
+	m, first := m.setVolume(m.pb.volume + 5)
+	m, second := m.setVolume(m.pb.volume + 5)
+	if m.pb.volume != 50 {
+		t.Fatalf("volume after two presses = %d, want 50", m.pb.volume)
+	}
+	// The older request fails first: the newer optimistic value stays.
+	m, _ = run(t, m, first)
+	if m.pb.volume != 50 {
+		t.Errorf("volume after the first failure = %d, want 50", m.pb.volume)
+	}
+	// The newer one fails too: back to what was shown before it.
+	m, _ = run(t, m, second)
+	if m.pb.volume != 45 {
+		t.Errorf("volume after the second failure = %d, want 45", m.pb.volume)
+	}
+}
+
+func TestPartialRepeatFailureResyncs(t *testing.T) {
+	// Turning context repeat off works, turning track repeat on does not, so
+	// the daemon ends up with repeat off.
+	pc := failingDaemon(t, `{"repeat_context": false, "repeat_track": false}`, "/player/repeat_track")
+	m := New(pc, nil, nil, &player.Status{RepeatContext: true}, nil)
+
+	cmd := m.cycleRepeat()
+	if m.pb.repeat != "track" {
+		t.Fatalf("repeat after cycling = %q, want track", m.pb.repeat)
+	}
+	m, resync := run(t, m, cmd)
+	if m.notice == "" {
+		t.Error("no notice after the failure")
+	}
+	m, follow := run(t, m, resync)
+	if m.pb.repeat != "off" {
+		t.Errorf("repeat after resync = %q, want off (the daemon's state)", m.pb.repeat)
+	}
+	if follow != nil {
+		t.Error("the resync poll scheduled another poll")
+	}
+}
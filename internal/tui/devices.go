@@ -0,0 +1,181 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"cli_spotify/internal/webapi"
+)
+
+// devicesState holds the Connect devices view.
+type devicesState struct {
+	devices []webapi.Device
+	cursor  int
+	status  string
+}
+
+// devicesMsg carries the Connect device list.
+type devicesMsg struct {
+	devices []webapi.Device
+	err     error
+}
+
+// transferMsg carries the outcome of moving playback to a device.
+type transferMsg struct {
+	name string
+	err  error
+}
+
+// loadDevices fetches the Connect device list.
+func loadDevices(web *webapi.Client) tea.Cmd {
+	return func() tea.Msg {
+		devices, err := web.Devices()
+		return devicesMsg{devices: devices, err: err}
+	}
+}
+
+// transferTo moves playback to a device, continuing to play there.
+func transferTo(web *webapi.Client, d webapi.Device) tea.Cmd {
+	return func() tea.Msg {
+		return transferMsg{name: d.Name, err: web.TransferPlayback(d.ID, true)}
+	}
+}
+
+// enterDevices switches to the devices view and (re)loads the list.
+func (m Model) enterDevices() (Model, tea.Cmd) {
+	m.view = viewDevices
+	m.devices.status = "Loading devices..."
+	return m, loadDevices(m.web)
+}
+
+// applyDevices fills the device list, keeping the cursor on the same device.
+func (m Model) applyDevices(msg devicesMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m.devices.status = "Error: " + msg.err.Error()
+		return m, nil
+	}
+	var selected string
+	if m.devices.cursor < len(m.devices.devices) {
+		selected = m.devices.devices[m.devices.cursor].ID
+	}
+	m.devices.devices = msg.devices
+	m.devices.cursor = 0
+	for i, d := range msg.devices {
+		if d.ID == selected {
+			m.devices.cursor = i
+		}
+	}
+	m.devices.status = ""
+	if len(msg.devices) == 0 {
+		m.devices.status = "No Connect devices are online."
+	}
+	return m, nil
+}
+
+// applyTransfer reports a transfer and reloads the list so the active
+// marker follows the playback. A failure replaces the "Moving playback..."
+// status.
+func (m Model) applyTransfer(msg transferMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m.devices.status = "Transfer to " + msg.name + " failed: " + msg.err.Error()
+		return m, nil
+	}
+	m.notice = "Playback moved to " + msg.name
+	return m, loadDevices(m.web)
+}
+
+// isLocalDevice reports whether d is this CLI's go-librespot device.
+func (m Model) isLocalDevice(d webapi.Device) bool {
+	if m.pb.deviceID != "" {
+		return d.ID == m.pb.deviceID
+	}
+	return m.pb.deviceName != "" && d.Name == m.pb.deviceName
+}
+
+// handleDevicesKey processes key events in the devices view.
+func (m Model) handleDevicesKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.view = viewNowPlaying
+		return m, nil
+	case "up", "k":
+		if m.devices.cursor > 0 {
+			m.devices.cursor--
+		}
+	case "down", "j":
+		if m.devices.cursor < len(m.devices.devices)-1 {
+			m.devices.cursor++
+		}
+	case "r":
+		return m.enterDevices()
+	case "enter":
+		if m.devices.cursor >= len(m.devices.devices) {
+			return m, nil
+		}
+		d := m.devices.devices[m.devices.cursor]
+		if d.IsRestricted {
+			m.notice = d.Name + " cannot be controlled from here."
+			return m, nil
+		}
+		m.devices.status = "Moving playback to " + d.Name + "..."
+		return m, transferTo(m.web, d)
+	case "h":
+		// Take playback over to this CLI, wherever it is playing now.
+		for _, d := range m.devices.devices {
+			if m.isLocalDevice(d) {
+				m.devices.status = "Moving playback here..."
+				return m, transferTo(m.web, d)
+			}
+		}
+		m.notice = "This CLI's device is not in the list yet; press [r] to refresh."
+	}
+	return m, nil
+}
+
+// devicesView renders the Connect devices screen.
+func (m Model) devicesView() string {
+	var b strings.Builder
+	b.WriteString("\n")
+	b.WriteString(titleStyle.Render("  ♪ DEVICES") + "\n\n")
+
+	if m.devices.status != "" {
+		b.WriteString(dimStyle.Render("  "+m.devices.status) + "\n\n")
+	}
+
+	for i, d := range m.devices.devices {
+		marker := "   "
+		if d.IsActive {
+			marker = "▶  "
+		}
+		line := marker + truncate(d.Name, 30) + dimSep + d.Type
+		if d.VolumePercent != nil {
+			line += dimSep + "vol " + strconv.Itoa(*d.VolumePercent) + "%"
+		}
+		var tags []string
+		if m.isLocalDevice(d) {
+			tags = append(tags, "this CLI")
+		}
+		if d.IsPrivateSession {
+			tags = append(tags, "private")
+		}
+		if d.IsRestricted {
+			tags = append(tags, "restricted")
+		}
+		if len(tags) > 0 {
+			line += "  (" + strings.Join(tags, ", ") + ")"
+		}
+		if i == m.devices.cursor {
+			b.WriteString(greenStyle.Render("  ▸ "+line) + "\n")
+		} else {
+			b.WriteString("    " + dimStyle.Render(line) + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  [↑↓] move  [enter] play on device  [h] play here  [r] refresh  [esc] back") + "\n")
+	return b.String()
+}
@@ -0,0 +1,20 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFailedTransferReplacesStatus(t *testing.T) {
+	m := New(nil, nil, nil, nil, nil)
+	m.devices.status = "Moving playback to Kitchen..."
+
+	m, cmd := m.applyTransfer(transferMsg{name: "Kitchen", err: errors.New("device not found")})
+	if cmd != nil {
+		t.Error("a failed transfer reloaded the device list")
+	}
+	if !strings.Contains(m.devices.status, "failed: device not found") {
+		t.Errorf("status = %q, want the transfer error", m.devices.status)
+	}
+}
@@ -0,0 +1,390 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"cli_spotify/internal/webapi"
+)
+
+// pickerState holds the "add to playlist…" picker: the track being added and
+// the playlists the user can edit.
+type pickerState struct {
+	trackURI  string
+	trackName string
+	playlists []webapi.Playlist
+	cursor    int // 0 is "New playlist…"
+	status    string
+	back      view
+}
+
+// promptState is a one-line text prompt shown at the bottom of the screen,
+// used to name new playlists and rename existing ones.
+type promptState struct {
+	input  textinput.Model
+	active bool
+	action string // "create" or "rename"
+}
+
+// removal is a track removed from a playlist this session, kept so it can be
+// undone. The API removes every occurrence of a URI, so positions lists them
+// all, in ascending order.
+type removal struct {
+	playlistURI string
+	track       webapi.Track
+	positions   []int
+}
+
+// playlistEdit is a change to the open playlist. Edits are applied to the
+// list as soon as they are made but sent one at a time: each request uses
+// the snapshot the previous one produced, and positions computed on a list
+// that already includes the previous edits.
+type playlistEdit struct {
+	desc     string
+	call     func(snapshot string) (string, error)
+	rollback func(*playlistState) // undoes the optimistic change on failure
+	done     func(*Model)         // runs on success, even if the playlist was left
+}
+
+// editable reports whether the current user may edit p.
+func (m Model) editable(p webapi.Playlist) bool {
+	return p.Collaborative || (m.userID != "" && p.Owner.ID == m.userID)
+}
+
+// openPicker shows the playlist picker for adding a track.
+func (m Model) openPicker(trackURI, trackName string) (Model, tea.Cmd) {
+	if trackURI == "" {
+		return m, nil
+	}
+	m.picker = pickerState{
+		trackURI:  trackURI,
+		trackName: trackName,
+		status:    "Loading playlists...",
+		back:      m.view,
+	}
+	m.view = viewPicker
+	return m, loadEditablePlaylists(m.web)
+}
+
+// applyEditablePlaylists fills the picker with the playlists the user owns or
+// collaborates on.
+func (m Model) applyEditablePlaylists(msg editablePlaylistsMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m.picker.status = "Failed: " + msg.err.Error()
+		return m, nil
+	}
+	m.userID = msg.userID
+	m.picker.playlists = m.picker.playlists[:0]
+	for _, p := range msg.playlists {
+		if m.editable(p) {
+			m.picker.playlists = append(m.picker.playlists, p)
+		}
+	}
+	m.picker.status = ""
+	return m, nil
+}
+
+// handlePickerKey processes key events in the playlist picker.
+func (m Model) handlePickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.view = m.picker.back
+		return m, nil
+	case "up", "k":
+		if m.picker.cursor > 0 {
+			m.picker.cursor--
+		}
+	case "down", "j":
+		if m.picker.cursor < len(m.picker.playlists) {
+			m.picker.cursor++
+		}
+	case "enter":
+		if m.picker.cursor == 0 {
+			return m.startPrompt("create", "new playlist name...", "")
+		}
+		p := m.picker.playlists[m.picker.cursor-1]
+		m.view = m.picker.back
+		return m, addToPlaylist(m.web, p, m.picker.trackURI, m.picker.trackName)
+	}
+	return m, nil
+}
+
+// pickerView renders the playlist picker.
+func (m Model) pickerView() string {
+	var b strings.Builder
+	b.WriteString("\n")
+	b.WriteString(titleStyle.Render("  ♪ ADD TO PLAYLIST") + "\n")
+	b.WriteString(dimStyle.Render("  "+truncate(m.picker.trackName, 60)) + "\n\n")
+
+	if m.picker.status != "" {
+		b.WriteString(dimStyle.Render("  "+m.picker.status) + "\n\n")
+	}
+
+	visible := max(3, m.height-10)
+	start := 0
+	if m.picker.cursor >= visible {
+		start = m.picker.cursor - visible + 1
+	}
+	end := min(start+visible, len(m.picker.playlists)+1)
+	for i := start; i < end; i++ {
+		line := "＋ New playlist..."
+		if i > 0 {
+			line = "≡  " + truncate(m.picker.playlists[i-1].Name, 55)
+		}
+		if i == m.picker.cursor {
+			b.WriteString(greenStyle.Render("  ▸ "+line) + "\n")
+		} else {
+			b.WriteString("    " + dimStyle.Render(line) + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  [↑↓] move  [enter] add  [esc] cancel") + "\n")
+	return b.String()
+}
+
+// startPrompt opens the text prompt for the given action.
+func (m Model) startPrompt(action, placeholder, value string) (Model, tea.Cmd) {
+	ti := textinput.New()
+	ti.Prompt = "  ✎ "
+	ti.Placeholder = placeholder
+	ti.CharLimit = 100
+	ti.SetValue(value)
+	m.prompt = promptState{input: ti, active: true, action: action}
+	return m, m.prompt.input.Focus()
+}
+
+// handlePromptKey routes keys while the text prompt is open.
+func (m Model) handlePromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.prompt.active = false
+		return m, nil
+	case "enter":
+		name := strings.TrimSpace(m.prompt.input.Value())
+		m.prompt.active = false
+		if name == "" {
+			return m, nil
+		}
+		switch m.prompt.action {
+		case "create":
+			addURI, addName := "", ""
+			if m.view == viewPicker {
+				addURI, addName = m.picker.trackURI, m.picker.trackName
+				m.view = m.picker.back
+			}
+			return m, createPlaylist(m.web, name, addURI, addName)
+		case "rename":
+			old, uri, web := m.playlist.name, m.playlist.uri, m.web
+			m.playlist.name = name
+			return m.queueEdit(playlistEdit{
+				desc: "Renamed to " + name,
+				call: func(string) (string, error) {
+					return "", web.ChangePlaylistDetails(uri, webapi.PlaylistDetails{Name: &name})
+				},
+				rollback: func(p *playlistState) { p.name = old },
+			})
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.prompt.input, cmd = m.prompt.input.Update(msg)
+	return m, cmd
+}
+
+// removeTrack removes the highlighted track from the open playlist, showing
+// the result immediately and restoring it if the request fails. Once the
+// removal succeeds it can be undone with [u], unless some of the playlist was
+// hidden or not loaded: the positions of those copies are unknown.
+func (m Model) removeTrack() (Model, tea.Cmd) {
+	t := m.selectedPlaylistTrack()
+	if t == nil || !m.playlist.editable {
+		return m, nil
+	}
+	track := *t
+	prev := m.playlist.tracks
+
+	// The API removes every occurrence of the URI, so mirror that locally.
+	kept := make([]webapi.Track, 0, len(prev))
+	var positions []int
+	for i, x := range prev {
+		if x.LibraryURI() == track.LibraryURI() {
+			positions = append(positions, i)
+		} else {
+			kept = append(kept, x)
+		}
+	}
+	m.playlist.tracks = kept
+	m.playlist.cursor = max(0, min(m.playlist.cursor, len(kept)-1))
+
+	uri, web := m.playlist.uri, m.web
+	desc := "Removed " + track.Name
+	if len(positions) > 1 {
+		desc = fmt.Sprintf("Removed %d copies of %s", len(positions), track.Name)
+	}
+	var done func(*Model)
+	if m.playlist.complete {
+		desc += " ([u] undo)"
+		done = func(m *Model) {
+			m.undo = append(m.undo, removal{playlistURI: uri, track: track, positions: positions})
+		}
+	}
+	return m.queueEdit(playlistEdit{
+		desc: desc,
+		call: func(snap string) (string, error) {
+			return web.RemovePlaylistItems(uri, []string{track.LibraryURI()}, snap)
+		},
+		rollback: func(p *playlistState) { p.tracks = prev },
+		done:     done,
+	})
+}
+
+// undoRemoval re-inserts the most recent removal from the open playlist,
+// every occurrence at its original position.
+func (m Model) undoRemoval() (Model, tea.Cmd) {
+	// The positions are only valid in the whole list, as they were recorded.
+	if !m.playlist.complete {
+		m.notice = "Load the whole playlist to undo a removal (some items are hidden or not loaded)."
+		return m, nil
+	}
+	for i := len(m.undo) - 1; i >= 0; i-- {
+		r := m.undo[i]
+		if r.playlistURI != m.playlist.uri {
+			continue
+		}
+		m.undo = append(m.undo[:i:i], m.undo[i+1:]...)
+		prev := m.playlist.tracks
+
+		// Inserting in ascending order puts each copy back where it was.
+		tracks := append([]webapi.Track(nil), prev...)
+		positions := make([]int, len(r.positions))
+		for j, pos := range r.positions {
+			pos = min(pos, len(tracks))
+			positions[j] = pos
+			tracks = append(tracks[:pos], append([]webapi.Track{r.track}, tracks[pos:]...)...)
+		}
+		m.playlist.tracks = tracks
+		m.playlist.cursor = positions[0]
+
+		uri, web := m.playlist.uri, m.web
+		return m.queueEdit(playlistEdit{
+			desc: "Restored " + r.track.Name,
+			call: func(string) (string, error) {
+				var snap string
+				for _, pos := range positions {
+					s, err := web.AddPlaylistItems(uri, []string{r.track.LibraryURI()}, pos)
+					if err != nil {
+						return snap, err
+					}
+					snap = s
+				}
+				return snap, nil
+			},
+			rollback: func(p *playlistState) { p.tracks = prev },
+		})
+	}
+	m.notice = "Nothing to undo in this playlist."
+	return m, nil
+}
+
+// moveTrack moves the highlighted track up (dir -1) or down (dir 1).
+func (m Model) moveTrack(dir int) (Model, tea.Cmd) {
+	i, j := m.playlist.cursor, m.playlist.cursor+dir
+	if !m.playlist.editable || i < 0 || j < 0 || j >= len(m.playlist.tracks) {
+		return m, nil
+	}
+	// Positions in the list only match playlist positions when every item
+	// was loaded and none was filtered out (e.g. unavailable tracks).
+	if !m.playlist.complete {
+		m.notice = "Load the whole playlist to reorder it (some items are hidden or not loaded)."
+		return m, nil
+	}
+
+	prev := m.playlist.tracks
+	tracks := append([]webapi.Track(nil), prev...)
+	tracks[i], tracks[j] = tracks[j], tracks[i]
+	m.playlist.tracks = tracks
+	m.playlist.cursor = j
+
+	insertBefore := j
+	if dir > 0 {
+		insertBefore = j + 1
+	}
+	uri, web := m.playlist.uri, m.web
+	return m.queueEdit(playlistEdit{
+		call: func(snap string) (string, error) {
+			return web.ReorderPlaylistItems(uri, i, insertBefore, 1, snap)
+		},
+		rollback: func(p *playlistState) {
+			p.tracks = prev
+			p.cursor = i
+		},
+	})
+}
+
+// queueEdit queues an edit of the open playlist, sending it right away if no
+// other edit is in flight.
+func (m Model) queueEdit(e playlistEdit) (Model, tea.Cmd) {
+	m.playlist.edits = append(m.playlist.edits, e)
+	if len(m.playlist.edits) > 1 {
+		return m, nil
+	}
+	return m, m.sendEdit()
+}
+
+// sendEdit sends the first queued edit against the current snapshot.
+func (m Model) sendEdit() tea.Cmd {
+	e, snap := m.playlist.edits[0], m.playlist.snapshot
+	cmd := editPlaylist(e.desc, m.playlist.uri, func() (string, error) { return e.call(snap) }, e.rollback)
+	return func() tea.Msg {
+		msg := cmd().(playlistEditMsg)
+		msg.done, msg.queued = e.done, true
+		return msg
+	}
+}
+
+// applyPlaylistEdit records the outcome of a playlist edit, rolling back the
+// optimistic change on failure.
+//
+// A failed edit of the open playlist also drops the edits queued behind it:
+// they were computed on a list that assumed it would succeed, and its
+// rollback restores the list from before it, without them.
+func (m Model) applyPlaylistEdit(msg playlistEditMsg) (Model, tea.Cmd) {
+	same := msg.playlistURI == m.playlist.uri
+	if same && msg.queued && len(m.playlist.edits) > 0 {
+		m.playlist.edits = m.playlist.edits[1:]
+	}
+	if msg.err != nil {
+		if same && msg.rollback != nil {
+			msg.rollback(&m.playlist)
+			m.playlist.cursor = max(0, min(m.playlist.cursor, len(m.playlist.tracks)-1))
+		}
+		m.notice = "Playlist edit failed: " + msg.err.Error()
+		if n := len(m.playlist.edits); same && n > 0 {
+			m.notice += fmt.Sprintf(" (%d later edits discarded)", n)
+			m.playlist.edits = nil
+		}
+		return m, nil
+	}
+	if same && msg.snapshot != "" {
+		m.playlist.snapshot = msg.snapshot
+	}
+	if msg.done != nil {
+		msg.done(&m)
+	}
+	m.syncLibrary()
+	if msg.desc != "" {
+		m.notice = msg.desc
+	}
+	if same && msg.queued && len(m.playlist.edits) > 0 {
+		return m, m.sendEdit()
+	}
+	return m, nil
+}
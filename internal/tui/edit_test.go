@@ -0,0 +1,243 @@
+package tui
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"cli_spotify/internal/library"
+	"cli_spotify/internal/webapi"
+	"cli_spotify/internal/webapi/webapitest"
+)
+
+// allScopes is every scope the Web API client asks for; a saved token with
+// fewer would start an interactive login.
+const allScopes = "user-read-playback-state user-modify-playback-state user-read-currently-playing playlist-read-private playlist-read-collaborative playlist-modify-public playlist-modify-private user-library-read user-library-modify user-read-recently-played user-top-read user-read-playback-position user-read-private"
+
+// fakeWeb returns a Web API client logged in to srv.
+func fakeWeb(t *testing.T, srv *webapitest.Server) *webapi.Client {
+	t.Helper()
+	store := webapi.NewFileStore(filepath.Join(t.TempDir(), "token.json"))
+	access, refresh, expiry := srv.IssueToken(time.Hour, allScopes)
+	if err := store.Save(&webapi.Token{AccessToken: access, RefreshToken: refresh, Expiry: expiry, Scope: allScopes}); err != nil {
+		t.Fatal(err)
+	}
+	auth := webapi.NewAuthenticator(webapitest.ClientID, "http://127.0.0.1:8888/callback", store)
+	auth.UseEndpoints(webapi.Endpoints{API: srv.APIBase(), Authorize: srv.AuthorizeURL(), Token: srv.TokenURL()})
+	web, err := webapi.NewClient(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(web.Close)
+	return web
+}
+
+// openPlaylist returns a model showing the fake's first playlist, which is
+// set to the given track IDs.
+func openPlaylist(t *testing.T, ids ...string) (Model, *webapitest.Server) {
+	t.Helper()
+	srv := webapitest.New()
+	t.Cleanup(srv.Close)
+	var tracks []webapitest.Track
+	for _, id := range ids {
+		tracks = append(tracks, webapitest.NewTrack(id, "Song "+strings.ToUpper(id), "Artist"))
+	}
+	srv.Playlists[0].Tracks = tracks
+
+	m := New(nil, fakeWeb(t, srv), nil, nil, nil)
+	const uri = "spotify:playlist:pl1"
+	items, err := m.web.PlaylistTracks(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.playlist = playlistState{uri: uri, tracks: items, editable: true, complete: true}
+	return m, srv
+}
+
+func shownIDs(m Model) []string {
+	var ids []string
+	for _, t := range m.playlist.tracks {
+		ids = append(ids, strings.TrimPrefix(t.URI, "spotify:track:"))
+	}
+	return ids
+}
+
+func serverIDs(srv *webapitest.Server) []string {
+	var ids []string
+	for _, t := range srv.Playlists[0].Tracks {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+func TestPlaylistEditsAreSentInOrder(t *testing.T) {
+	m, srv := openPlaylist(t, "a", "b", "c", "d")
+
+	m.playlist.cursor = 0
+	m, move := m.moveTrack(1) // b a c d
+	m, remove := m.removeTrack()
+	if remove != nil {
+		t.Fatal("the second edit was sent while the first was in flight")
+	}
+	want := []string{"b", "c", "d"} // the cursor followed a down, so a is removed
+	if got := shownIDs(m); !slices.Equal(got, want) {
+		t.Fatalf("shown = %q, want %q", got, want)
+	}
+
+	m, next := run(t, m, move)
+	m, last := run(t, m, next)
+	if last != nil {
+		t.Error("an edit was sent with nothing queued")
+	}
+	if got := serverIDs(srv); !slices.Equal(got, want) {
+		t.Errorf("server = %q, want %q", got, want)
+	}
+	if len(m.undo) != 1 {
+		t.Errorf("%d undo entries, want 1", len(m.undo))
+	}
+}
+
+func TestUndoRestoresEveryCopy(t *testing.T) {
+	m, srv := openPlaylist(t, "a", "b", "a", "c")
+
+	m, cmd := m.removeTrack()
+	m, _ = run(t, m, cmd)
+	if got, want := serverIDs(srv), []string{"b", "c"}; !slices.Equal(got, want) {
+		t.Fatalf("server after removal = %q, want %q", got, want)
+	}
+
+	m, cmd = m.undoRemoval()
+	m, _ = run(t, m, cmd)
+	want := []string{"a", "b", "a", "c"}
+	if got := shownIDs(m); !slices.Equal(got, want) {
+		t.Errorf("shown after undo = %q, want %q", got, want)
+	}
+	if got := serverIDs(srv); !slices.Equal(got, want) {
+		t.Errorf("server after undo = %q, want %q", got, want)
+	}
+}
+
+func TestFailedRemovalRollsBackAndDiscardsQueue(t *testing.T) {
+	m, srv := openPlaylist(t, "a", "b", "c")
+	srv.Fail("/playlists/pl1/items", webapitest.Failure{Status: 403})
+
+	m, cmd := m.removeTrack()
+	m, _ = m.moveTrack(1)
+	m, follow := run(t, m, cmd)
+	if follow != nil {
+		t.Error("the queued edit was sent after the failure")
+	}
+	if got, want := shownIDs(m), []string{"a", "b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("shown = %q, want %q", got, want)
+	}
+	if len(m.undo) != 0 {
+		t.Error("a failed removal can be undone")
+	}
+	if !strings.Contains(m.notice, "1 later edits discarded") {
+		t.Errorf("notice = %q, want it to mention the discarded edit", m.notice)
+	}
+	if len(m.playlist.edits) != 0 {
+		t.Errorf("%d edits still queued", len(m.playlist.edits))
+	}
+}
+
+func TestNoUndoOnPartialPlaylist(t *testing.T) {
+	m, srv := openPlaylist(t, "a", "b", "a", "c")
+	m.playlist.complete = false // e.g. a later page is not loaded yet
+
+	m, cmd := m.removeTrack()
+	m, _ = run(t, m, cmd)
+	if len(m.undo) != 0 {
+		t.Error("a removal from a partial list can be undone")
+	}
+	if strings.Contains(m.notice, "undo") {
+		t.Errorf("notice = %q, want no undo offered", m.notice)
+	}
+
+	m.undo = []removal{{playlistURI: m.playlist.uri, track: webapi.Track{URI: "spotify:track:a"}, positions: []int{0, 2}}}
+	m, cmd = m.undoRemoval()
+	if cmd != nil {
+		t.Error("undo was sent on a partial list")
+	}
+	if got, want := serverIDs(srv), []string{"b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("server = %q, want %q", got, want)
+	}
+}
+
+func TestUndoIsRecordedAfterLeavingThePlaylist(t *testing.T) {
+	m, srv := openPlaylist(t, "a", "b", "a")
+	uri := m.playlist.uri
+
+	m, cmd := m.removeTrack()
+	m.playlist = playlistState{uri: "spotify:playlist:pl2"} // left before the reply
+	m, _ = run(t, m, cmd)
+	if len(m.undo) != 1 {
+		t.Fatalf("%d undo entries, want 1", len(m.undo))
+	}
+
+	items, err := m.web.PlaylistTracks(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.playlist = playlistState{uri: uri, tracks: items, editable: true, complete: true}
+	m, cmd = m.undoRemoval()
+	m, _ = run(t, m, cmd)
+	if got, want := serverIDs(srv), []string{"a", "b", "a"}; !slices.Equal(got, want) {
+		t.Errorf("server after undo = %q, want %q", got, want)
+	}
+}
+
+func TestReorderMirroredPlaylist(t *testing.T) {
+	for _, withLocal := range []bool{false, true} {
+		srv := webapitest.New()
+		t.Cleanup(srv.Close)
+		pl := &srv.Playlists[0]
+		pl.Tracks = []webapitest.Track{webapitest.NewTrack("a", "Song A", "Artist"), webapitest.NewTrack("b", "Song B", "Artist")}
+		if withLocal {
+			// Local files are left out of the mirror, so list positions no
+			// longer match playlist positions.
+			local := webapitest.Track{ID: "local", Name: "Home Recording", URI: "spotify:local:Artist:Album:Home+Recording:120"}
+			pl.Tracks = slices.Insert(pl.Tracks, 0, local)
+		}
+		before := serverIDs(srv)
+
+		web := fakeWeb(t, srv)
+		mirror, err := library.Open(filepath.Join(t.TempDir(), "library.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		syncer := library.NewSyncer(web, mirror, time.Hour)
+		if err := syncer.Sync(); err != nil {
+			t.Fatal(err)
+		}
+		m := New(nil, web, nil, nil, syncer)
+		m.userID = "tester"
+		m.library.playlists = mirror.Playlists()
+		m.library.cursor = 1 // the first playlist, after Liked Songs
+		m, cmd := m.openLibraryEntry()
+		m, _ = run(t, m, cmd)
+		if got, want := shownIDs(m), []string{"a", "b"}; !slices.Equal(got, want) {
+			t.Fatalf("local=%v: shown = %q, want %q", withLocal, got, want)
+		}
+
+		m, cmd = m.moveTrack(1)
+		if !withLocal {
+			m, _ = run(t, m, cmd)
+			if got, want := serverIDs(srv), []string{"b", "a"}; !slices.Equal(got, want) {
+				t.Errorf("server after reorder = %q, want %q", got, want)
+			}
+			continue
+		}
+		if cmd != nil {
+			t.Error("a reorder was sent for a playlist with hidden items")
+		}
+		if got := serverIDs(srv); !slices.Equal(got, before) {
+			t.Errorf("server = %q, want it unchanged (%q)", got, before)
+		}
+		if !strings.Contains(m.notice, "Load the whole playlist") {
+			t.Errorf("notice = %q, want the reorder refused", m.notice)
+		}
+	}
+}
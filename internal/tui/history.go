@@ -0,0 +1,300 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"cli_spotify/internal/webapi"
+)
+
+// History view tabs.
+const (
+	historyRecent = iota
+	historyTopTracks
+	historyTopArtists
+)
+
+var historyTabs = []string{"Recently played", "Top tracks", "Top artists"}
+
+// timeRanges is the order [t] cycles the top lists through.
+var timeRanges = []webapi.TimeRange{webapi.ShortTerm, webapi.MediumTerm, webapi.LongTerm}
+
+// historyState holds the listening history view: recently played tracks and
+// the user's top tracks and artists over a selectable time range.
+type historyState struct {
+	tab     int
+	rng     webapi.TimeRange
+	recent  []webapi.PlayHistory
+	tracks  []webapi.Track
+	artists []webapi.Artist
+	cursors [3]int
+	loading bool
+	status  string
+}
+
+// recentMsg carries the recently played list.
+type recentMsg struct {
+	recent []webapi.PlayHistory
+	err    error
+}
+
+// topMsg carries the top tracks and artists for a time range.
+type topMsg struct {
+	rng     webapi.TimeRange
+	tracks  []webapi.Track
+	artists []webapi.Artist
+	err     error
+}
+
+// loadRecent fetches the recently played list.
+func loadRecent(web *webapi.Client) tea.Cmd {
+	return func() tea.Msg {
+		recent, err := web.RecentlyPlayed(0)
+		return recentMsg{recent: recent, err: err}
+	}
+}
+
+// loadTop fetches the top tracks and artists for r.
+func loadTop(web *webapi.Client, r webapi.TimeRange) tea.Cmd {
+	return func() tea.Msg {
+		tracks, err := web.TopTracks(r)
+		if err != nil {
+			return topMsg{rng: r, err: err}
+		}
+		artists, err := web.TopArtists(r)
+		return topMsg{rng: r, tracks: tracks, artists: artists, err: err}
+	}
+}
+
+// enterHistory switches to the history view. The recently played list is
+// refetched on every visit; the top lists only when not yet loaded.
+func (m Model) enterHistory() (Model, tea.Cmd) {
+	m.view = viewHistory
+	m.history.status = "Loading..."
+	m.history.cursors[historyRecent] = 0
+	cmds := []tea.Cmd{loadRecent(m.web)}
+	if m.history.rng == "" {
+		m.history.rng = webapi.ShortTerm
+		m.history.loading = true
+		cmds = append(cmds, loadTop(m.web, m.history.rng))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// applyRecent fills the recently played tab.
+func (m Model) applyRecent(msg recentMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m.history.status = "Error: " + msg.err.Error()
+		return m, nil
+	}
+	m.history.recent = msg.recent
+	m.history.status = ""
+	uris := make([]string, len(msg.recent))
+	for i, h := range msg.recent {
+		uris[i] = h.Track.URI
+	}
+	return m, m.refreshSaved(uris...)
+}
+
+// applyTop fills the top tabs, ignoring results for a range the user has
+// since moved away from.
+func (m Model) applyTop(msg topMsg) (Model, tea.Cmd) {
+	if msg.rng != m.history.rng {
+		return m, nil
+	}
+	m.history.loading = false
+	if msg.err != nil {
+		m.history.status = "Error: " + msg.err.Error()
+		return m, nil
+	}
+	m.history.tracks = msg.tracks
+	m.history.artists = msg.artists
+	m.history.cursors[historyTopTracks] = 0
+	m.history.cursors[historyTopArtists] = 0
+	return m, m.refreshSaved(trackURIs(msg.tracks)...)
+}
+
+// count returns the number of rows in tab.
+func (h *historyState) count(tab int) int {
+	switch tab {
+	case historyRecent:
+		return len(h.recent)
+	case historyTopTracks:
+		return len(h.tracks)
+	}
+	return len(h.artists)
+}
+
+// selectedTrack returns the highlighted track on a track tab, or nil.
+func (h *historyState) selectedTrack() *webapi.Track {
+	cur := h.cursors[h.tab]
+	switch {
+	case h.tab == historyRecent && cur < len(h.recent):
+		return &h.recent[cur].Track
+	case h.tab == historyTopTracks && cur < len(h.tracks):
+		return &h.tracks[cur]
+	}
+	return nil
+}
+
+// handleHistoryKey processes key events in the history view.
+func (m Model) handleHistoryKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	h := &m.history
+	cur := &h.cursors[h.tab]
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.view = viewNowPlaying
+		return m, nil
+	case "tab", "right", "l":
+		h.tab = (h.tab + 1) % len(historyTabs)
+	case "shift+tab", "left", "h":
+		h.tab = (h.tab + len(historyTabs) - 1) % len(historyTabs)
+	case "up", "k":
+		if *cur > 0 {
+			*cur--
+		}
+	case "down", "j":
+		if *cur < h.count(h.tab)-1 {
+			*cur++
+		}
+	case "t":
+		for i, r := range timeRanges {
+			if r == h.rng {
+				h.rng = timeRanges[(i+1)%len(timeRanges)]
+				break
+			}
+		}
+		h.loading = true
+		return m, loadTop(m.web, h.rng)
+	case "a":
+		return m.openTrackArtist(h.selectedTrack(), viewHistory)
+	case "f":
+		if t := h.selectedTrack(); t != nil {
+			return m.toggleLiked(t.LibraryURI())
+		}
+	case "+":
+		if t := h.selectedTrack(); t != nil {
+			return m.openPicker(t.LibraryURI(), t.Name)
+		}
+	case "e", "E":
+		return m.enqueue(h.selectedTrack(), msg.String() == "E")
+	case "enter":
+		switch h.tab {
+		case historyRecent:
+			if t := h.selectedTrack(); t != nil {
+				if why := unplayable(*t); why != "" {
+					m.notice = why
+					return m, nil
+				}
+				return m, playTrack(m.pc, "", t.URI, t.Name)
+			}
+		case historyTopTracks:
+			// Play the top tracks from the highlighted one onwards.
+			if *cur < len(h.tracks) {
+				if why := unplayable(h.tracks[*cur]); why != "" {
+					m.notice = why
+					return m, nil
+				}
+				return m, playURIs(m.pc, playableURIs(h.tracks[*cur:]), h.tracks[*cur].Name)
+			}
+		case historyTopArtists:
+			if *cur < len(h.artists) {
+				a := h.artists[*cur]
+				return m.openArtist(a.URI, a.Name, viewHistory)
+			}
+		}
+	}
+	return m, nil
+}
+
+// historyView renders the history screen.
+func (m Model) historyView() string {
+	h := &m.history
+	var b strings.Builder
+	b.WriteString("\n")
+	b.WriteString(titleStyle.Render("  ♪ HISTORY") + "\n\n")
+
+	tabs := make([]string, len(historyTabs))
+	for i, title := range historyTabs {
+		if i != historyRecent {
+			title += " (" + h.rng.Label() + ")"
+		}
+		if i == h.tab {
+			tabs[i] = greenStyle.Render("[" + title + "]")
+		} else {
+			tabs[i] = dimStyle.Render(" " + title + " ")
+		}
+	}
+	b.WriteString("  " + strings.Join(tabs, " ") + "\n\n")
+
+	if h.status != "" {
+		b.WriteString(dimStyle.Render("  "+h.status) + "\n\n")
+	}
+
+	n := h.count(h.tab)
+	if n == 0 {
+		msg := "Nothing here yet."
+		if h.loading && h.tab != historyRecent {
+			msg = "Loading..."
+		}
+		b.WriteString(dimStyle.Render("  "+msg) + "\n\n")
+		b.WriteString(helpStyle.Render("  [tab] switch list  [t] time range  [esc] back") + "\n")
+		return b.String()
+	}
+
+	cur := h.cursors[h.tab]
+	visible := m.height - 10
+	if visible < 3 {
+		visible = 3
+	}
+	start := 0
+	if cur >= visible {
+		start = cur - visible + 1
+	}
+	end := min(start+visible, n)
+
+	now := time.Now()
+	for i := start; i < end; i++ {
+		var line string
+		switch h.tab {
+		case historyRecent:
+			e := h.recent[i]
+			line = m.heart(e.Track.LibraryURI()) + playedAt(e.PlayedAt, now) + "  " +
+				truncate(e.Track.Name, 35) + dimSep + truncate(e.Track.ArtistNames(), 25)
+			b.WriteString(trackRow(line, e.Track, i == cur))
+			continue
+		case historyTopTracks:
+			t := h.tracks[i]
+			line = m.heart(t.LibraryURI()) + strconv.Itoa(i+1) + ". " + truncate(t.Name, 40) + dimSep + truncate(t.ArtistNames(), 25)
+			b.WriteString(trackRow(line, t, i == cur))
+			continue
+		default:
+			line = strconv.Itoa(i+1) + ". " + truncate(h.artists[i].Name, 55)
+		}
+		if i == cur {
+			b.WriteString(greenStyle.Render("  ▸ "+line) + "\n")
+		} else {
+			b.WriteString("    " + dimStyle.Render(line) + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  [↑↓] move  [←→/tab] list  [t] time range  [enter] play/open  [a] artist  [f] like  [e/E] queue/next  [+] add to playlist  [esc] back") + "\n")
+	return b.String()
+}
+
+// playedAt formats a play time compactly: the time of day for today's plays,
+// otherwise the date as well.
+func playedAt(t, now time.Time) string {
+	t = t.Local()
+	y, mo, d := now.Date()
+	if ty, tm, td := t.Date(); ty == y && tm == mo && td == d {
+		return "     " + t.Format("15:04")
+	}
+	return t.Format("Jan _2 15:04")
+}
@@ -8,18 +8,35 @@ import (
 	"cli_spotify/internal/webapi"
 )
 
-// libraryState holds the library view state (albums + playlists).
+// loadAhead is how close (in rows) the cursor may get to the end of a list
+// before its next page is requested.
+const loadAhead = 10
+
+// libraryState holds the library view state (albums, playlists and
+// podcasts). Albums and playlists come from the library mirror once it has
+// been synced; otherwise, like podcasts, they are paged from the Web API,
+// with further pages loading as the cursor approaches the end.
 type libraryState struct {
-	albums    []webapi.Album
-	playlists []webapi.Playlist
-	cursor    int
-	loaded    bool
-	status    string
+	albums     []webapi.Album
+	playlists  []webapi.Playlist
+	shows      []webapi.Show
+	cursor     int
+	loaded     bool
+	fromMirror bool // albums and playlists are the mirror's
+	status     string
+
+	albumPager       *webapi.Pager[webapi.Album]
+	playlistPager    *webapi.Pager[webapi.Playlist]
+	showPager        *webapi.Pager[webapi.Show]
+	loadingAlbums    bool
+	loadingPlaylists bool
+	loadingShows     bool
 }
 
-// libraryTotal returns the total number of entries (Liked Songs + albums + playlists).
+// libraryTotal returns the total number of entries (Liked Songs + albums +
+// playlists + podcasts).
 func (l *libraryState) total() int {
-	return 1 + len(l.albums) + len(l.playlists)
+	return 1 + len(l.albums) + len(l.playlists) + len(l.shows)
 }
 
 // playlistState holds the open-playlist (track list) view state.
@@ -30,19 +47,189 @@ type playlistState struct {
 	tracks  []webapi.Track
 	cursor  int
 	status  string
+	pager   *webapi.Pager[webapi.Track]
+	loading bool
+	back    view // screen to return to on esc
+
+	editable bool           // the user may add, remove and reorder items
+	snapshot string         // playlist version the edits apply to
+	complete bool           // every item is loaded and list index == playlist position
+	edits    []playlistEdit // queued edits; the first is in flight
 }
 
-// enterLibrary switches to the library view, triggering a playlist load on
-// first visit.
+// enterLibrary switches to the library view. On first visit it reads albums
+// and playlists from the mirror if one has been synced, and requests their
+// first pages otherwise.
 func (m Model) enterLibrary() (Model, tea.Cmd) {
 	m.view = viewLibrary
+	if !m.library.loaded && m.mirrorSynced() {
+		m.library.loaded = true
+		m.reloadFromMirror()
+		m.library.showPager = m.web.SavedShowsPager()
+		m.library.loadingShows = true
+		return m, loadShowsPage(m.library.showPager)
+	}
 	if !m.library.loaded {
+		m.library.loaded = true
 		m.library.status = "Loading playlists..."
-		return m, loadPlaylists(m.web)
+		m.library.albumPager = m.web.SavedAlbumsPager()
+		m.library.playlistPager = m.web.UserPlaylistsPager()
+		m.library.showPager = m.web.SavedShowsPager()
+		m.library.loadingAlbums = true
+		m.library.loadingPlaylists = true
+		m.library.loadingShows = true
+		return m, tea.Batch(
+			loadAlbumsPage(m.library.albumPager),
+			loadPlaylistsPage(m.library.playlistPager),
+			loadShowsPage(m.library.showPager),
+		)
 	}
 	return m, nil
 }
 
+// mirrorSynced reports whether there is a library mirror with a completed
+// sync to read from.
+func (m Model) mirrorSynced() bool {
+	return m.sync != nil && !m.sync.Mirror().Synced().IsZero()
+}
+
+// reloadFromMirror replaces albums and playlists with the mirror's, keeping
+// the cursor on the same entry where it still exists. Paging them from the
+// Web API stops.
+func (m *Model) reloadFromMirror() {
+	l := &m.library
+	key := libraryKey(l, l.cursor)
+	mirror := m.sync.Mirror()
+	l.albums, l.playlists = mirror.Albums(), mirror.Playlists()
+	l.albumPager, l.playlistPager = nil, nil
+	l.loadingAlbums, l.loadingPlaylists = false, false
+	l.fromMirror = true
+	l.status = ""
+	l.cursor = min(l.cursor, l.total()-1)
+	for i := range l.total() {
+		if libraryKey(l, i) == key {
+			l.cursor = i
+			break
+		}
+	}
+}
+
+// libraryKey identifies the library entry at row i by URI.
+func libraryKey(l *libraryState, i int) string {
+	switch {
+	case i <= 0:
+		return "liked"
+	case i-1 < len(l.albums):
+		return l.albums[i-1].URI
+	case i-1-len(l.albums) < len(l.playlists):
+		return l.playlists[i-1-len(l.albums)].URI
+	case i-1-len(l.albums)-len(l.playlists) < len(l.shows):
+		return l.shows[i-1-len(l.albums)-len(l.playlists)].URI
+	}
+	return ""
+}
+
+// applySync shows sync progress and brings a mirror-backed library view up
+// to date with each change. A library paged from the Web API switches over
+// to the mirror once a sync completes.
+func (m Model) applySync(msg syncMsg) (Model, tea.Cmd) {
+	p := msg.p
+	m.syncNote = p.String()
+	if p.Finished && p.Err == nil {
+		m.syncNote = "" // syncLine shows when
+	}
+	switch {
+	case m.library.fromMirror && p.Changed:
+		m.reloadFromMirror()
+	case m.library.loaded && p.Finished && p.Err == nil:
+		m.reloadFromMirror()
+	}
+	return m, listenSync(m.sync.Progress())
+}
+
+// syncLibrary asks for a sync pass soon, after edits that make the mirror
+// stale. It does nothing without a mirror.
+func (m Model) syncLibrary() {
+	if m.sync != nil {
+		m.sync.SyncNow()
+	}
+}
+
+// applyAlbumsPage appends a page of saved albums. Albums are listed before
+// playlists, so a cursor already in the playlists section is shifted to stay
+// on the same entry.
+func (m Model) applyAlbumsPage(msg albumsPageMsg) (Model, tea.Cmd) {
+	if m.library.fromMirror {
+		return m, nil // requested before the mirror took over
+	}
+	m.library.loadingAlbums = false
+	if msg.err != nil {
+		m.library.status = "Failed: " + msg.err.Error()
+		return m, nil
+	}
+	if m.library.cursor > len(m.library.albums) {
+		m.library.cursor += len(msg.albums)
+	}
+	m.library.albums = append(m.library.albums, msg.albums...)
+	m.library.status = ""
+	return m.loadMoreLibrary()
+}
+
+// applyPlaylistsPage appends a page of the user's playlists, shifting a
+// cursor in the podcasts section below them like applyAlbumsPage does.
+func (m Model) applyPlaylistsPage(msg playlistsPageMsg) (Model, tea.Cmd) {
+	if m.library.fromMirror {
+		return m, nil
+	}
+	m.library.loadingPlaylists = false
+	if msg.err != nil {
+		m.library.status = "Failed: " + msg.err.Error()
+		return m, nil
+	}
+	if m.library.cursor > len(m.library.albums)+len(m.library.playlists) {
+		m.library.cursor += len(msg.playlists)
+	}
+	m.library.playlists = append(m.library.playlists, msg.playlists...)
+	m.library.status = ""
+	return m.loadMoreLibrary()
+}
+
+// applyShowsPage appends a page of saved podcasts. Apps without podcast
+// access get an error here; the rest of the library still works, so it only
+// hides the section.
+func (m Model) applyShowsPage(msg showsPageMsg) (Model, tea.Cmd) {
+	m.library.loadingShows = false
+	if msg.err != nil {
+		m.library.showPager = nil
+		return m, nil
+	}
+	m.library.shows = append(m.library.shows, msg.shows...)
+	return m.loadMoreLibrary()
+}
+
+// loadMoreLibrary requests the next page of albums or playlists when the
+// cursor is within loadAhead rows of the end of that section.
+func (m Model) loadMoreLibrary() (Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	l := &m.library
+	if l.albumPager != nil && !l.loadingAlbums && !l.albumPager.Done() &&
+		l.cursor >= len(l.albums)-loadAhead {
+		l.loadingAlbums = true
+		cmds = append(cmds, loadAlbumsPage(l.albumPager))
+	}
+	if l.playlistPager != nil && !l.loadingPlaylists && !l.playlistPager.Done() &&
+		l.cursor >= len(l.albums)+len(l.playlists)-loadAhead {
+		l.loadingPlaylists = true
+		cmds = append(cmds, loadPlaylistsPage(l.playlistPager))
+	}
+	if l.showPager != nil && !l.loadingShows && !l.showPager.Done() &&
+		l.cursor >= l.total()-1-loadAhead {
+		l.loadingShows = true
+		cmds = append(cmds, loadShowsPage(l.showPager))
+	}
+	return m, tea.Batch(cmds...)
+}
+
 // handleLibraryKey processes key events in the library view.
 func (m Model) handleLibraryKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
@@ -59,8 +246,16 @@ func (m Model) handleLibraryKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.library.cursor < m.library.total()-1 {
 			m.library.cursor++
 		}
+		return m.loadMoreLibrary()
 	case "enter":
 		return m.openLibraryEntry()
+	case "n":
+		return m.startPrompt("create", "new playlist name...", "")
+	case "S":
+		if m.sync != nil {
+			m.sync.SyncNow()
+			m.syncNote = "Library sync requested"
+		}
 	}
 	return m, nil
 }
@@ -69,23 +264,89 @@ func (m Model) handleLibraryKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // Liked Songs and Albums show individual tracks.
 // Playlists try to load tracks; on 403 (dev mode restriction for non-owned
 // playlists) the playlistTracksMsg handler falls back to direct play.
+// Podcasts open their episode list.
 func (m Model) openLibraryEntry() (Model, tea.Cmd) {
 	if m.library.cursor == 0 {
-		m.playlist = playlistState{name: "♥ Liked Songs", isLiked: true, status: "Loading..."}
-		m.view = viewPlaylist
-		return m, loadLikedSongs(m.web)
+		m.playlist = playlistState{name: "♥ Liked Songs", isLiked: true}
+		if m.mirrorSynced() {
+			liked := m.sync.Mirror().Liked()
+			return m.openTrackList(webapi.StaticPager(liked, len(liked)), viewLibrary)
+		}
+		return m.openTrackList(m.web.SavedTracksPager(), viewLibrary)
 	}
 	i := m.library.cursor - 1
 	if i < len(m.library.albums) {
 		a := m.library.albums[i]
-		m.playlist = playlistState{name: a.Name, uri: a.URI, status: "Loading..."}
-		m.view = viewPlaylist
-		return m, loadAlbumTracks(m.web, a.ID)
+		m.playlist = playlistState{name: a.Name, uri: a.URI}
+		return m.openTrackList(m.web.AlbumTracksPager(a.ID), viewLibrary)
+	}
+	i -= len(m.library.albums)
+	if i >= len(m.library.playlists) {
+		return m.openShow(m.library.shows[i-len(m.library.playlists)], viewLibrary)
 	}
-	pl := m.library.playlists[i-len(m.library.albums)]
-	m.playlist = playlistState{name: pl.Name, uri: pl.URI, status: "Loading..."}
+	pl := m.library.playlists[i]
+	m.playlist = playlistState{name: pl.Name, uri: pl.URI, editable: m.editable(pl), snapshot: pl.SnapshotID}
+	if m.sync != nil {
+		if tracks, total, ok := m.sync.Mirror().PlaylistItems(pl.URI); ok {
+			return m.openTrackList(webapi.StaticPager(tracks, total), viewLibrary)
+		}
+	}
+	return m.openTrackList(m.web.PlaylistTracksPager(pl.URI), viewLibrary)
+}
+
+// openTrackList switches to the track list view and requests its first page.
+// back is the screen esc returns to.
+func (m Model) openTrackList(p *webapi.Pager[webapi.Track], back view) (Model, tea.Cmd) {
 	m.view = viewPlaylist
-	return m, loadPlaylistTracks(m.web, pl.URI)
+	m.playlist.back = back
+	m.playlist.status = "Loading..."
+	m.playlist.pager = p
+	m.playlist.loading = true
+	return m, loadTracksPage(p)
+}
+
+// applyTracksPage appends a page of tracks to the open track list.
+func (m Model) applyTracksPage(msg playlistTracksMsg) (Model, tea.Cmd) {
+	if msg.pager != m.playlist.pager {
+		return m, nil // the user has since opened another list
+	}
+	m.playlist.loading = false
+	if msg.err != nil {
+		// 403 on non-owned playlists in dev mode — play the playlist directly
+		// as a context URI instead of showing individual tracks.
+		if len(m.playlist.tracks) == 0 && m.playlist.uri != "" {
+			m.view = viewNowPlaying
+			return m, playTrack(m.pc, m.playlist.uri, "", m.playlist.name)
+		}
+		m.playlist.status = "Error: " + msg.err.Error()
+		return m, nil
+	}
+	m.playlist.tracks = append(m.playlist.tracks, msg.tracks...)
+	m.playlist.status = ""
+	if msg.pager.Done() {
+		m.playlist.complete = msg.pager.Total() == len(m.playlist.tracks)
+	}
+	var check tea.Cmd
+	if m.playlist.isLiked {
+		for _, t := range msg.tracks {
+			m.liked[t.LibraryURI()] = true
+		}
+	} else {
+		check = m.refreshSaved(trackURIs(msg.tracks)...)
+	}
+	m, more := m.loadMoreTracks()
+	return m, tea.Batch(more, check)
+}
+
+// loadMoreTracks requests the next page of the open track list when the
+// cursor is within loadAhead rows of its end.
+func (m Model) loadMoreTracks() (Model, tea.Cmd) {
+	p := &m.playlist
+	if p.pager == nil || p.loading || p.pager.Done() || p.cursor < len(p.tracks)-loadAhead {
+		return m, nil
+	}
+	p.loading = true
+	return m, loadTracksPage(p.pager)
 }
 
 // handlePlaylistKey processes key events in the open-playlist (track list) view.
@@ -94,7 +355,7 @@ func (m Model) handlePlaylistKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "ctrl+c":
 		return m, tea.Quit
 	case "esc":
-		m.view = viewLibrary
+		m.view = m.playlist.back
 		return m, nil
 	case "up", "k":
 		if m.playlist.cursor > 0 {
@@ -104,11 +365,40 @@ func (m Model) handlePlaylistKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.playlist.cursor < len(m.playlist.tracks)-1 {
 			m.playlist.cursor++
 		}
+		return m.loadMoreTracks()
 	case "enter":
 		if t := m.selectedPlaylistTrack(); t != nil {
+			if why := unplayable(*t); why != "" {
+				m.playlist.status = why
+				return m, nil
+			}
 			m.playlist.status = "Playing: " + t.Name
 			return m, playTrack(m.pc, "", t.URI, t.Name)
 		}
+	case "a":
+		return m.openTrackArtist(m.selectedPlaylistTrack(), viewPlaylist)
+	case "f":
+		if t := m.selectedPlaylistTrack(); t != nil {
+			return m.toggleLiked(t.LibraryURI())
+		}
+	case "e", "E":
+		return m.enqueue(m.selectedPlaylistTrack(), msg.String() == "E")
+	case "+":
+		if t := m.selectedPlaylistTrack(); t != nil {
+			return m.openPicker(t.LibraryURI(), t.Name)
+		}
+	case "x":
+		return m.removeTrack()
+	case "u":
+		return m.undoRemoval()
+	case "K":
+		return m.moveTrack(-1)
+	case "J":
+		return m.moveTrack(1)
+	case "R":
+		if m.playlist.editable {
+			return m.startPrompt("rename", "playlist name...", m.playlist.name)
+		}
 	}
 	return m, nil
 }
@@ -156,9 +446,12 @@ func (m Model) libraryView() string {
 		case i-1 < len(m.library.albums):
 			a := m.library.albums[i-1]
 			line = "♫  " + truncate(a.Name, 38) + dimSep + truncate(a.ArtistNames(), 20)
-		default:
+		case i-1-len(m.library.albums) < len(m.library.playlists):
 			pl := m.library.playlists[i-1-len(m.library.albums)]
 			line = "≡  " + truncate(pl.Name, 55)
+		default:
+			sh := m.library.shows[i-1-len(m.library.albums)-len(m.library.playlists)]
+			line = "🎙  " + truncate(sh.Name, 38) + dimSep + truncate(sh.Publisher, 20)
 		}
 		if i == m.library.cursor {
 			b.WriteString(greenStyle.Render("  ▸ "+line) + "\n")
@@ -167,11 +460,36 @@ func (m Model) libraryView() string {
 		}
 	}
 
+	if m.library.loadingAlbums || m.library.loadingPlaylists || m.library.loadingShows {
+		b.WriteString(dimStyle.Render("    loading more...") + "\n")
+	}
+
+	if m.sync != nil {
+		b.WriteString(dimStyle.Render("    "+m.syncLine()) + "\n")
+	}
+
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("  [↑↓] move  [enter] open  [esc] back  (♥ liked  ♫ album  ≡ playlist)") + "\n")
+	help := "  [↑↓] move  [enter] open  [n] new playlist  [esc] back  (♥ liked  ♫ album  ≡ playlist  🎙 podcast)"
+	if m.sync != nil {
+		help = "  [↑↓] move  [enter] open  [n] new playlist  [S] sync now  [esc] back  (♥ liked  ♫ album  ≡ playlist  🎙 podcast)"
+	}
+	b.WriteString(helpStyle.Render(help) + "\n")
 	return b.String()
 }
 
+// syncLine describes the library sync: progress while a pass runs, then when
+// the mirror was last synced.
+func (m Model) syncLine() string {
+	synced := m.sync.Mirror().Synced()
+	switch {
+	case m.syncNote != "":
+		return m.syncNote
+	case synced.IsZero():
+		return "Library not synced yet"
+	}
+	return "Library synced " + synced.Format("15:04")
+}
+
 // playlistView renders the open-playlist (track list) screen.
 func (m Model) playlistView() string {
 	var b strings.Builder
@@ -208,15 +526,23 @@ func (m Model) playlistView() string {
 
 	for i := start; i < end; i++ {
 		t := m.playlist.tracks[i]
-		line := truncate(t.Name, 45) + dimSep + truncate(t.ArtistNames(), 30)
-		if i == cur {
-			b.WriteString(greenStyle.Render("  ▸ "+line) + "\n")
-		} else {
-			b.WriteString("    " + dimStyle.Render(line) + "\n")
+		marker := m.heart(t.LibraryURI())
+		if t.IsEpisode() {
+			marker = "🎙"
 		}
+		line := marker + truncate(t.Name, 43) + dimSep + truncate(t.ArtistNames(), 30)
+		b.WriteString(trackRow(line, t, i == cur))
+	}
+
+	if m.playlist.loading {
+		b.WriteString(dimStyle.Render("    loading more...") + "\n")
 	}
 
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("  [↑↓] move  [enter] play  [esc] back") + "\n")
+	help := "  [↑↓] move  [enter] play  [a] artist  [f] like  [e/E] queue/next  [+] add to playlist  [esc] back"
+	if m.playlist.editable {
+		help = "  [↑↓] move  [enter] play  [a] artist  [f] like  [e/E] queue/next  [+] add to  [x] remove  [u] undo  [J/K] reorder  [R] rename  [esc] back"
+	}
+	b.WriteString(helpStyle.Render(help) + "\n")
 	return b.String()
 }
@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"cli_spotify/internal/webapi"
+)
+
+// savedMsg carries Liked Songs membership for a batch of track URIs.
+type savedMsg struct {
+	saved map[string]bool
+	err   error
+}
+
+// likeMsg carries the outcome of a like/unlike toggle.
+type likeMsg struct {
+	uri   string
+	liked bool // the state that was requested
+	err   error
+}
+
+// checkSaved looks up which of the given tracks are in Liked Songs.
+func checkSaved(web *webapi.Client, uris []string) tea.Cmd {
+	return func() tea.Msg {
+		states, err := web.SavedTracksContain(uris)
+		if err != nil {
+			return savedMsg{err: err}
+		}
+		saved := make(map[string]bool, len(uris))
+		for i, u := range uris {
+			if i < len(states) {
+				saved[u] = states[i]
+			}
+		}
+		return savedMsg{saved: saved}
+	}
+}
+
+// setLiked saves or removes a track from Liked Songs.
+func setLiked(web *webapi.Client, uri string, liked bool) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		if liked {
+			err = web.SaveTracks([]string{uri})
+		} else {
+			err = web.RemoveSavedTracks([]string{uri})
+		}
+		return likeMsg{uri: uri, liked: liked, err: err}
+	}
+}
+
+// refreshSaved returns a command checking the saved state of any track URIs
+// not yet known. Non-track URIs (episodes, local files) are skipped.
+func (m Model) refreshSaved(uris ...string) tea.Cmd {
+	if m.web == nil {
+		return nil
+	}
+	var unknown []string
+	for _, u := range uris {
+		if _, ok := m.liked[u]; !ok && isTrackURI(u) {
+			unknown = append(unknown, u)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	return checkSaved(m.web, unknown)
+}
+
+// trackURIs lists the URIs the library knows tracks by (see
+// webapi.Track.LibraryURI), for Liked Songs lookups.
+func trackURIs(tracks []webapi.Track) []string {
+	uris := make([]string, len(tracks))
+	for i, t := range tracks {
+		uris[i] = t.LibraryURI()
+	}
+	return uris
+}
+
+// toggleLiked flips the saved state of a track, showing the change
+// immediately and reverting it if the request fails.
+func (m Model) toggleLiked(uri string) (Model, tea.Cmd) {
+	known, ok := m.liked[uri]
+	if !ok || !isTrackURI(uri) {
+		return m, nil // state not loaded yet
+	}
+	m.liked[uri] = !known
+	return m, setLiked(m.web, uri, !known)
+}
+
+// applySaved records looked-up Liked Songs membership. A failed lookup leaves
+// the tracks unknown, so their hearts stay blank and [f] waits for a retry.
+func (m Model) applySaved(msg savedMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m.notice = "Checking Liked Songs failed: " + msg.err.Error()
+		return m, nil
+	}
+	for uri, saved := range msg.saved {
+		m.liked[uri] = saved
+	}
+	return m, nil
+}
+
+// applyLike records the outcome of a toggle.
+func (m Model) applyLike(msg likeMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m.liked[msg.uri] = !msg.liked
+		m.notice = "Updating Liked Songs failed: " + msg.err.Error()
+		return m, nil
+	}
+	if msg.liked {
+		m.notice = "Added to Liked Songs"
+	} else {
+		m.notice = "Removed from Liked Songs"
+	}
+	m.syncLibrary()
+	return m, nil
+}
+
+// heart renders the saved-state marker for a track row.
+func (m Model) heart(uri string) string {
+	if m.liked[uri] {
+		return "♥ "
+	}
+	return "  "
+}
+
+func isTrackURI(uri string) bool {
+	return strings.HasPrefix(uri, "spotify:track:")
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"cli_spotify/internal/player"
+	"cli_spotify/internal/webapi/webapitest"
+)
+
+// settle runs cmd and feeds every message it produces back into m, except
+// the event listener's.
+func settle(m Model, cmd tea.Cmd) Model {
+	if cmd == nil {
+		return m
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			m = settle(m, c)
+		}
+	case playerEventMsg:
+	default:
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	return m
+}
+
+func lookups(srv *webapitest.Server) int {
+	n := 0
+	for _, r := range srv.Requests() {
+		if r == "GET /me/tracks/contains" {
+			n++
+		}
+	}
+	return n
+}
+
+func TestFailedSavedLookupIsNotRepeatedPerEvent(t *testing.T) {
+	srv := webapitest.New()
+	defer srv.Close()
+	fail := webapitest.Failure{Status: 404}
+	srv.Fail("/me/tracks/contains", fail, fail, fail)
+	events := make(chan player.Event)
+	close(events) // listenEvents returns at once
+	m := New(nil, fakeWeb(t, srv), events, nil, nil)
+
+	metadata := func(uri string) player.Event {
+		data, _ := json.Marshal(player.EventMetadata{URI: uri, Name: "Song"})
+		return player.Event{Type: "metadata", Data: data}
+	}
+	feed := func(ev player.Event) {
+		next, cmd := m.Update(playerEventMsg{ev: ev, ok: true})
+		m = settle(next.(Model), cmd)
+	}
+	feed(metadata("spotify:track:liked1"))
+	if !strings.Contains(m.notice, "Checking Liked Songs failed") {
+		t.Fatalf("notice = %q, want the failed lookup reported", m.notice)
+	}
+	m.notice = ""
+	feed(player.Event{Type: "playing"})
+	feed(player.Event{Type: "volume", Data: json.RawMessage(`{"value": 10, "max": 100}`)})
+	if n := lookups(srv); n != 1 {
+		t.Errorf("%d lookups for one track, want 1", n)
+	}
+	if m.notice != "" {
+		t.Errorf("notice = %q, want the failure not repeated", m.notice)
+	}
+
+	feed(metadata("spotify:track:liked2"))
+	if n := lookups(srv); n != 2 {
+		t.Errorf("%d lookups after a track change, want 2", n)
+	}
+}
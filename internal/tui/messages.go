@@ -1,18 +1,39 @@
 package tui
 
 import (
+	"fmt"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"cli_spotify/internal/library"
 	"cli_spotify/internal/player"
 	"cli_spotify/internal/webapi"
 )
 
-// tickMsg fires once per second to advance the local progress bar between
+// tickMsg fires once per second to redraw the progress bar between
 // WebSocket events.
 type tickMsg time.Time
 
+// reconcileInterval is how often the playback position is re-read from the
+// daemon's REST status to correct drift.
+const reconcileInterval = 5 * time.Second
+
+// reconcileMsg triggers a status poll.
+type reconcileMsg struct{}
+
+// statusMsg carries a polled /status snapshot. sent is when the request was
+// issued and at the estimated moment the daemon sampled its position (the
+// midpoint of the round trip). resync marks a one-off poll outside the
+// periodic reconcile loop.
+type statusMsg struct {
+	status *player.Status
+	sent   time.Time
+	at     time.Time
+	err    error
+	resync bool
+}
+
 // playerEventMsg carries a go-librespot WebSocket event into the Bubble Tea
 // update loop. ok is false when the event stream has closed.
 type playerEventMsg struct {
@@ -23,9 +44,9 @@ type playerEventMsg struct {
 // listenEvents returns a command that blocks until the next daemon event and
 // delivers it as a playerEventMsg. It is re-issued after each event to keep
 // reading the stream.
-func listenEvents(events *player.EventHandler) tea.Cmd {
+func listenEvents(events <-chan player.Event) tea.Cmd {
 	return func() tea.Msg {
-		ev, ok := <-events.Ch
+		ev, ok := <-events
 		return playerEventMsg{ev: ev, ok: ok}
 	}
 }
@@ -37,10 +58,96 @@ func tickCmd() tea.Cmd {
 	})
 }
 
-// searchResultsMsg carries the outcome of a track search.
+// reconcileCmd schedules the next status poll.
+func reconcileCmd() tea.Cmd {
+	return tea.Tick(reconcileInterval, func(time.Time) tea.Msg {
+		return reconcileMsg{}
+	})
+}
+
+// fetchStatus polls GET /status on the daemon.
+func fetchStatus(pc *player.Client) tea.Cmd {
+	return func() tea.Msg {
+		sent := time.Now()
+		s, err := pc.Status()
+		at := sent.Add(time.Since(sent) / 2)
+		return statusMsg{status: s, sent: sent, at: at, err: err}
+	}
+}
+
+// resyncStatus polls /status once, e.g. after a failed control request, so
+// the UI shows what the daemon actually did. It is a no-op when replaying.
+func resyncStatus(pc *player.Client) tea.Cmd {
+	if pc == nil {
+		return nil
+	}
+	fetch := fetchStatus(pc)
+	return func() tea.Msg {
+		msg := fetch().(statusMsg)
+		msg.resync = true
+		return msg
+	}
+}
+
+// controlMsg carries the outcome of a playback control request (play/pause,
+// volume, shuffle, repeat, skip). The UI applies the expected change up front;
+// rollback, when set, undoes it if the daemon rejected the request.
+type controlMsg struct {
+	action   string
+	err      error
+	rollback func(*playback)
+}
+
+// control runs a daemon call off the UI loop and reports its outcome.
+func control(action string, call func() error, rollback func(*playback)) tea.Cmd {
+	return func() tea.Msg {
+		return controlMsg{action: action, err: call(), rollback: rollback}
+	}
+}
+
+// retryMsg reports that a Web API request is being retried.
+type retryMsg struct {
+	ev webapi.RetryEvent
+}
+
+// syncMsg reports progress of the background library sync.
+type syncMsg struct {
+	p library.Progress
+}
+
+// clearNoticeMsg clears the notice line if it still shows text.
+type clearNoticeMsg struct {
+	text string
+}
+
+// listenRetries returns a command that delivers the next Web API retry
+// notification. It is re-issued after each one.
+func listenRetries(retries <-chan webapi.RetryEvent) tea.Cmd {
+	return func() tea.Msg {
+		return retryMsg{ev: <-retries}
+	}
+}
+
+// listenSync returns a command that delivers the next library sync progress
+// report. It is re-issued after each one.
+func listenSync(progress <-chan library.Progress) tea.Cmd {
+	return func() tea.Msg {
+		return syncMsg{p: <-progress}
+	}
+}
+
+// clearNoticeAfter clears a transient notice once it is no longer accurate.
+func clearNoticeAfter(d time.Duration, text string) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return clearNoticeMsg{text: text}
+	})
+}
+
+// searchResultsMsg carries the outcome of a multi-type search.
 type searchResultsMsg struct {
-	tracks []webapi.Track
-	err    error
+	query   string
+	results *webapi.SearchResults
+	err     error
 }
 
 // playResultMsg carries the outcome of a play request.
@@ -49,24 +156,202 @@ type playResultMsg struct {
 	err   error
 }
 
-// playlistListMsg carries the user's albums and playlists from the Web API.
-type playlistListMsg struct {
-	albums    []webapi.Album
+// albumsPageMsg carries a page of the user's saved albums.
+type albumsPageMsg struct {
+	albums []webapi.Album
+	err    error
+}
+
+// playlistsPageMsg carries a page of the user's playlists.
+type playlistsPageMsg struct {
 	playlists []webapi.Playlist
 	err       error
 }
 
-// playlistTracksMsg carries the tracks for an open playlist or Liked Songs.
+// showsPageMsg carries a page of the user's saved podcasts.
+type showsPageMsg struct {
+	shows []webapi.Show
+	err   error
+}
+
+// episodesPageMsg carries a page of a show's episodes.
+type episodesPageMsg struct {
+	pager    *webapi.Pager[webapi.Episode]
+	episodes []webapi.Episode
+	err      error
+}
+
+// playlistTracksMsg carries a page of tracks for an open playlist, album or
+// Liked Songs. pager identifies the list it belongs to, so a page arriving
+// after the user opened something else is dropped.
 type playlistTracksMsg struct {
+	pager  *webapi.Pager[webapi.Track]
 	tracks []webapi.Track
 	err    error
 }
 
-// doSearch runs a track search on the Web API.
+// searchPageSize is how many results of each type a search page requests.
+// The client clamps it to the app's quota, and paging follows the limit the
+// API reports, so a smaller cap just means smaller pages.
+const searchPageSize = 20
+
+// searchMoreMsg carries a further page of one search result type.
+type searchMoreMsg struct {
+	query   string
+	tab     int
+	results *webapi.SearchResults
+	err     error
+}
+
+// doSearch searches every result type on the Web API.
 func doSearch(web *webapi.Client, query string) tea.Cmd {
 	return func() tea.Msg {
-		tracks, err := web.SearchTracks(query, 25)
-		return searchResultsMsg{tracks: tracks, err: err}
+		res, err := web.Search(query, webapi.AllSearchTypes, searchPageSize, 0)
+		return searchResultsMsg{query: query, results: res, err: err}
+	}
+}
+
+// doSearchMore fetches the page of one result type starting at offset.
+func doSearchMore(web *webapi.Client, query string, tab int, offset int) tea.Cmd {
+	return func() tea.Msg {
+		kind := searchTabs[tab].kind
+		res, err := web.Search(query, []webapi.SearchType{kind}, searchPageSize, offset)
+		return searchMoreMsg{query: query, tab: tab, results: res, err: err}
+	}
+}
+
+// artistMsg carries a loaded artist page.
+type artistMsg struct {
+	uri         string
+	artist      *webapi.Artist
+	top         []webapi.Track
+	discography *webapi.Discography
+	err         error
+}
+
+// trackArtistMsg carries the first artist of a track looked up by URI, for
+// "go to artist" on the now-playing screen (the daemon only reports names).
+type trackArtistMsg struct {
+	artist webapi.Artist
+	err    error
+}
+
+// loadArtist fetches an artist's details, top tracks and discography.
+func loadArtist(web *webapi.Client, artistURI string) tea.Cmd {
+	return func() tea.Msg {
+		msg := artistMsg{uri: artistURI}
+		if msg.artist, msg.err = web.Artist(artistURI); msg.err != nil {
+			return msg
+		}
+		if msg.top, msg.err = web.ArtistTopTracks(artistURI); msg.err != nil {
+			return msg
+		}
+		msg.discography, msg.err = web.ArtistAlbums(artistURI)
+		return msg
+	}
+}
+
+// loadTrackArtist looks up the first artist of a track.
+func loadTrackArtist(web *webapi.Client, trackURI string) tea.Cmd {
+	return func() tea.Msg {
+		t, err := web.Track(trackURI)
+		if err != nil {
+			return trackArtistMsg{err: err}
+		}
+		if len(t.Artists) == 0 {
+			return trackArtistMsg{err: fmt.Errorf("track has no artist")}
+		}
+		return trackArtistMsg{artist: t.Artists[0]}
+	}
+}
+
+// userMsg carries the current user's profile.
+type userMsg struct {
+	user *webapi.User
+}
+
+// editablePlaylistsMsg carries the user's playlists for the picker, along
+// with the user's ID so ownership can be checked.
+type editablePlaylistsMsg struct {
+	userID    string
+	playlists []webapi.Playlist
+	err       error
+}
+
+// playlistEditMsg carries the outcome of a playlist edit. snapshot is the new
+// playlist version; rollback undoes the optimistic change on failure. queued
+// marks edits of the open playlist sent from its edit queue.
+type playlistEditMsg struct {
+	desc        string
+	playlistURI string
+	snapshot    string
+	err         error
+	rollback    func(*playlistState)
+	done        func(*Model) // runs on success, whichever view is open by then
+	queued      bool
+}
+
+// playlistCreatedMsg carries a newly created playlist.
+type playlistCreatedMsg struct {
+	playlist *webapi.Playlist
+	added    string // name of the track added to it, if any
+	err      error
+}
+
+// loadUser fetches the current user's profile.
+func loadUser(web *webapi.Client) tea.Cmd {
+	return func() tea.Msg {
+		u, err := web.CurrentUser()
+		if err != nil {
+			return nil
+		}
+		return userMsg{user: u}
+	}
+}
+
+// loadEditablePlaylists fetches the current user and all their playlists.
+func loadEditablePlaylists(web *webapi.Client) tea.Cmd {
+	return func() tea.Msg {
+		u, err := web.CurrentUser()
+		if err != nil {
+			return editablePlaylistsMsg{err: err}
+		}
+		playlists, err := web.UserPlaylists()
+		return editablePlaylistsMsg{userID: u.ID, playlists: playlists, err: err}
+	}
+}
+
+// addToPlaylist appends a track to a playlist.
+func addToPlaylist(web *webapi.Client, p webapi.Playlist, trackURI, trackName string) tea.Cmd {
+	return editPlaylist("Added "+trackName+" to "+p.Name, p.URI, func() (string, error) {
+		return web.AddPlaylistItems(p.URI, []string{trackURI}, -1)
+	}, nil)
+}
+
+// createPlaylist creates a private playlist, optionally adding a first track.
+func createPlaylist(web *webapi.Client, name, trackURI, trackName string) tea.Cmd {
+	return func() tea.Msg {
+		p, err := web.CreatePlaylist(name, "", false)
+		if err != nil || trackURI == "" {
+			return playlistCreatedMsg{playlist: p, err: err}
+		}
+		_, err = web.AddPlaylistItems(p.URI, []string{trackURI}, -1)
+		return playlistCreatedMsg{playlist: p, added: trackName, err: err}
+	}
+}
+
+// editPlaylist runs a playlist edit off the UI loop.
+func editPlaylist(desc, playlistURI string, call func() (string, error), rollback func(*playlistState)) tea.Cmd {
+	return func() tea.Msg {
+		snap, err := call()
+		return playlistEditMsg{desc: desc, playlistURI: playlistURI, snapshot: snap, err: err, rollback: rollback}
+	}
+}
+
+// playURIs plays a list of tracks in order.
+func playURIs(pc *player.Client, uris []string, name string) tea.Cmd {
+	return func() tea.Msg {
+		return playResultMsg{track: name, err: pc.PlayURIs(uris)}
 	}
 }
 
@@ -83,39 +368,49 @@ func playTrack(pc *player.Client, contextURI, trackURI, name string) tea.Cmd {
 	}
 }
 
-// loadPlaylists fetches the user's saved albums and playlists from the Web API.
-func loadPlaylists(web *webapi.Client) tea.Cmd {
+// loadAlbumsPage fetches the next page of the user's saved albums.
+func loadAlbumsPage(p *webapi.Pager[webapi.Album]) tea.Cmd {
 	return func() tea.Msg {
-		albums, err := web.SavedAlbums()
-		if err != nil {
-			return playlistListMsg{err: err}
-		}
-		playlists, err := web.UserPlaylists()
-		return playlistListMsg{albums: albums, playlists: playlists, err: err}
+		albums, err := p.Next()
+		return albumsPageMsg{albums: albums, err: err}
 	}
 }
 
-// loadAlbumTracks fetches the tracks for a given album ID.
-func loadAlbumTracks(web *webapi.Client, albumID string) tea.Cmd {
+// loadPlaylistsPage fetches the next page of the user's playlists.
+func loadPlaylistsPage(p *webapi.Pager[webapi.Playlist]) tea.Cmd {
 	return func() tea.Msg {
-		tracks, err := web.AlbumTracks(albumID)
-		return playlistTracksMsg{tracks: tracks, err: err}
+		playlists, err := p.Next()
+		return playlistsPageMsg{playlists: playlists, err: err}
 	}
 }
 
-// loadPlaylistTracks fetches the tracks for a given playlist URI.
-func loadPlaylistTracks(web *webapi.Client, playlistURI string) tea.Cmd {
+// loadShowsPage fetches the next page of the user's saved podcasts.
+func loadShowsPage(p *webapi.Pager[webapi.Show]) tea.Cmd {
 	return func() tea.Msg {
-		tracks, err := web.PlaylistTracks(playlistURI)
-		return playlistTracksMsg{tracks: tracks, err: err}
+		shows, err := p.Next()
+		return showsPageMsg{shows: shows, err: err}
 	}
 }
 
-// loadLikedSongs fetches the user's saved (Liked Songs) tracks.
-func loadLikedSongs(web *webapi.Client) tea.Cmd {
+// loadEpisodesPage fetches the next page of a show's episodes.
+func loadEpisodesPage(p *webapi.Pager[webapi.Episode]) tea.Cmd {
 	return func() tea.Msg {
-		tracks, err := web.SavedTracks()
-		return playlistTracksMsg{tracks: tracks, err: err}
+		episodes, err := p.Next()
+		return episodesPageMsg{pager: p, episodes: episodes, err: err}
 	}
 }
 
+// playEpisode starts an episode from its resume point.
+func playEpisode(pc *player.Client, e webapi.Episode) tea.Cmd {
+	return func() tea.Msg {
+		return playResultMsg{track: e.Name, err: pc.PlayFrom(e.URI, e.Resume())}
+	}
+}
+
+// loadTracksPage fetches the next page of an open track list.
+func loadTracksPage(p *webapi.Pager[webapi.Track]) tea.Cmd {
+	return func() tea.Msg {
+		tracks, err := p.Next()
+		return playlistTracksMsg{pager: p, tracks: tracks, err: err}
+	}
+}
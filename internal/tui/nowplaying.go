@@ -19,6 +19,7 @@ var (
 	greenStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
 	yellowStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("220"))
 	helpStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	mutedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("238")) // unplayable rows
 )
 
 // nowPlayingView renders the now-playing screen.
@@ -29,16 +30,23 @@ func (m Model) nowPlayingView() string {
 		b.WriteString(titleStyle.Render("  ♪ NOW PLAYING") + "\n\n")
 		b.WriteString("  No track currently playing.\n")
 		b.WriteString(dimStyle.Render("  Press / to search or p to browse your library.") + "\n\n")
-		b.WriteString(helpStyle.Render("  [/] search  [p] library  [q] quit") + "\n")
+		if m.notice != "" {
+			b.WriteString(yellowStyle.Render("  [!] "+truncate(m.notice, 70)) + "\n\n")
+		}
+		b.WriteString(helpStyle.Render("  [/] search  [p] library  [H] history  [d] devices  [u] queue  [q] quit") + "\n")
 		return b.String()
 	}
 
-	bar := display.CreateProgressBar(m.pb.progress, m.pb.duration, 50)
-	cur := display.FormatDuration(m.pb.progress)
-	total := display.FormatDuration(m.pb.duration)
+	progress, duration := m.pb.pos.At(time.Now()), m.pb.pos.Duration()
+	bar := display.CreateProgressBar(progress, duration, 50)
+	cur := display.FormatDuration(progress)
+	total := display.FormatDuration(duration)
 
 	statusIcon, statusText := "⏸", "Paused"
-	if m.pb.isPlaying {
+	switch {
+	case m.pb.buffering:
+		statusIcon, statusText = "⋯", "Buffering"
+	case m.pb.isPlaying:
 		statusIcon, statusText = "▶", "Playing"
 	}
 
@@ -54,47 +62,75 @@ func (m Model) nowPlayingView() string {
 		repeat = "🔁"
 	}
 
+	title, album, artists := "  ♪ NOW PLAYING", m.pb.album, m.pb.artists
+	keys := "  [space] play/pause  [←→] prev/next  [↑↓] vol  [s] shuffle  [r] repeat  [a] artist  [f] like  [+] add to playlist"
+	if m.pb.isEpisode() {
+		// go-librespot reports an episode's show as its album and the
+		// publisher as its artist.
+		title, album, artists = "  🎙 NOW PLAYING", "Show: "+m.pb.album, "By "+m.pb.artists
+		keys = "  [space] play/pause  [←→] -15s/+30s  [<>] prev/next  [↑↓] vol"
+	}
+
 	var b strings.Builder
 	b.WriteString("\n")
-	b.WriteString(titleStyle.Render("  ♪ NOW PLAYING") + "\n\n")
-	b.WriteString(trackStyle.Render("  "+truncate(m.pb.trackName, 60)) + "\n")
-	b.WriteString(dimStyle.Render("  "+truncate(m.pb.artists, 60)) + "\n")
-	b.WriteString(dimStyle.Render("  "+truncate(m.pb.album, 60)) + "\n\n")
+	b.WriteString(titleStyle.Render(title) + "\n\n")
+	b.WriteString(trackStyle.Render("  " + truncate(m.pb.trackName, 60)))
+	if m.liked[m.pb.uri] {
+		b.WriteString(greenStyle.Render("  ♥"))
+	}
+	b.WriteString("\n")
+	b.WriteString(dimStyle.Render("  "+truncate(artists, 60)) + "\n")
+	b.WriteString(dimStyle.Render("  "+truncate(album, 60)) + "\n\n")
 	b.WriteString("  " + dimStyle.Render(cur) + " " + greenStyle.Render(bar) + " " + dimStyle.Render(total) + "\n\n")
 	b.WriteString("  " + greenStyle.Render(statusIcon+" "+statusText) +
 		"   " + yellowStyle.Render(shuffle) +
 		"   " + yellowStyle.Render(repeat) +
 		"   " + dimStyle.Render("vol "+strconv.Itoa(m.pb.volume)+"%") + "\n\n")
-	b.WriteString(helpStyle.Render("  [space] play/pause  [←→] prev/next  [↑↓] vol  [s] shuffle  [r] repeat  [/] search  [p] library  [q] quit") + "\n")
+	if m.notice != "" {
+		b.WriteString(yellowStyle.Render("  [!] "+truncate(m.notice, 70)) + "\n\n")
+	}
+	b.WriteString(helpStyle.Render(keys+"  [/] search  [p] library  [H] history  [d] devices  [u] queue  [q] quit") + "\n")
 	return b.String()
 }
 
-// applyEvent updates playback state from a WebSocket event.
-func (m *Model) applyEvent(ev player.Event) {
+// applyEvent updates playback state from a WebSocket event received at now.
+func (m *Model) applyEvent(ev player.Event, now time.Time) {
+	defer m.syncClock(now)
+
 	switch ev.Type {
 	case "metadata":
 		var d player.EventMetadata
 		if json.Unmarshal(ev.Data, &d) == nil {
+			m.pb.uri = d.URI
 			m.pb.trackName = d.Name
 			m.pb.artists = strings.Join(d.ArtistNames, ", ")
 			m.pb.album = d.AlbumName
-			m.pb.duration = time.Duration(d.Duration) * time.Millisecond
-			m.pb.progress = time.Duration(d.Position) * time.Millisecond
+			if d.ContextURI != "" {
+				m.pb.contextURI = d.ContextURI
+			}
+			m.pb.pos.SetDuration(time.Duration(d.Duration) * time.Millisecond)
+			m.pb.pos.Set(time.Duration(d.Position)*time.Millisecond, now)
 			m.pb.stopped = false
 		}
+	case "will_play":
+		// The next track is loading; position holds until "playing".
+		m.pb.buffering = true
 	case "playing":
 		m.pb.isPlaying = true
+		m.pb.buffering = false
 		m.pb.stopped = false
 	case "paused":
 		m.pb.isPlaying = false
-	case "stopped":
+		m.pb.buffering = false
+	case "not_playing", "stopped":
 		m.pb.isPlaying = false
-		m.pb.stopped = true
+		m.pb.buffering = false
+		m.pb.stopped = ev.Type == "stopped"
 	case "seek":
 		var d player.EventSeek
 		if json.Unmarshal(ev.Data, &d) == nil {
-			m.pb.progress = time.Duration(d.Position) * time.Millisecond
-			m.pb.duration = time.Duration(d.Duration) * time.Millisecond
+			m.pb.pos.SetDuration(time.Duration(d.Duration) * time.Millisecond)
+			m.pb.pos.Set(time.Duration(d.Position)*time.Millisecond, now)
 		}
 	case "volume":
 		var d player.EventVolume
@@ -124,15 +160,29 @@ func (m *Model) applyEvent(ev player.Event) {
 				m.pb.repeat = "off"
 			}
 		}
+	case "status":
+		// Synthetic event carrying a full /status snapshot (session replay).
+		var s player.Status
+		if json.Unmarshal(ev.Data, &s) == nil {
+			m.applyStatus(&s, now)
+		}
 	}
 }
 
-// applyStatus seeds playback state from the REST /status response.
-func (m *Model) applyStatus(s *player.Status) {
+// applyStatus sets playback state from a REST /status response whose position
+// was sampled at the given time.
+func (m *Model) applyStatus(s *player.Status, at time.Time) {
+	defer m.syncClock(at)
+
 	m.pb.isPlaying = !s.Paused && !s.Stopped
 	m.pb.stopped = s.Stopped
+	m.pb.buffering = s.Buffering
 	m.pb.shuffle = s.ShuffleContext
 	m.pb.volume = s.Volume
+	m.pb.contextURI = s.ContextURI
+	if s.DeviceID != "" || s.DeviceName != "" {
+		m.pb.deviceID, m.pb.deviceName = s.DeviceID, s.DeviceName
+	}
 
 	switch {
 	case s.RepeatTrack:
@@ -144,13 +194,25 @@ func (m *Model) applyStatus(s *player.Status) {
 	}
 
 	if s.Track != nil {
+		m.pb.uri = s.Track.URI
 		m.pb.trackName = s.Track.Name
 		m.pb.artists = strings.Join(s.Track.ArtistNames, ", ")
 		m.pb.album = s.Track.AlbumName
-		m.pb.duration = time.Duration(s.Track.Duration) * time.Millisecond
+		m.pb.pos.SetDuration(time.Duration(s.Track.Duration) * time.Millisecond)
+		m.pb.pos.Set(time.Duration(s.Track.Position)*time.Millisecond, at)
 	}
 }
 
+// syncClock runs the position clock only while audio is actually advancing.
+func (m *Model) syncClock(now time.Time) {
+	m.pb.pos.SetRunning(m.pb.isPlaying && !m.pb.buffering && !m.pb.stopped, now)
+}
+
+// isEpisode reports whether a podcast episode is playing.
+func (pb *playback) isEpisode() bool {
+	return strings.HasPrefix(pb.uri, "spotify:episode:")
+}
+
 func truncate(s string, max int) string {
 	r := []rune(s)
 	if len(r) <= max {
@@ -0,0 +1,41 @@
+package tui
+
+import "cli_spotify/internal/webapi"
+
+// trackRow renders a list row for t, highlighted when selected. Tracks that
+// cannot be played in the user's market are muted, even when highlighted,
+// and say why.
+func trackRow(line string, t webapi.Track, selected bool) string {
+	if !t.Playable() {
+		line += "  (" + t.UnplayableReason() + ")"
+		if selected {
+			return mutedStyle.Render("  ▸ "+line) + "\n"
+		}
+		return "    " + mutedStyle.Render(line) + "\n"
+	}
+	if selected {
+		return greenStyle.Render("  ▸ "+line) + "\n"
+	}
+	return "    " + dimStyle.Render(line) + "\n"
+}
+
+// unplayable returns a status line explaining why t cannot be played, or ""
+// if it can.
+func unplayable(t webapi.Track) string {
+	if t.Playable() {
+		return ""
+	}
+	return "Can't play " + t.Name + ": " + t.UnplayableReason()
+}
+
+// playableURIs lists the URIs of the tracks that can be played, skipping the
+// rest, for playing a run of tracks.
+func playableURIs(tracks []webapi.Track) []string {
+	var uris []string
+	for _, t := range tracks {
+		if t.Playable() {
+			uris = append(uris, t.URI)
+		}
+	}
+	return uris
+}
@@ -0,0 +1,176 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"cli_spotify/internal/display"
+	"cli_spotify/internal/webapi"
+)
+
+// showState holds the open-podcast (episode list) view state.
+type showState struct {
+	show     webapi.Show
+	episodes []webapi.Episode
+	cursor   int
+	status   string
+	pager    *webapi.Pager[webapi.Episode]
+	loading  bool
+	back     view // screen to return to on esc
+}
+
+// openShow switches to a show's episode list and requests its first page.
+func (m Model) openShow(sh webapi.Show, back view) (Model, tea.Cmd) {
+	p := m.web.ShowEpisodesPager(sh.URI)
+	m.show = showState{show: sh, status: "Loading...", pager: p, loading: true, back: back}
+	m.view = viewShow
+	return m, loadEpisodesPage(p)
+}
+
+// applyEpisodesPage appends a page of episodes to the open show.
+func (m Model) applyEpisodesPage(msg episodesPageMsg) (Model, tea.Cmd) {
+	if msg.pager != m.show.pager {
+		return m, nil // the user has since opened another show
+	}
+	m.show.loading = false
+	if msg.err != nil {
+		m.show.status = "Error: " + msg.err.Error()
+		return m, nil
+	}
+	for i := range msg.episodes {
+		if msg.episodes[i].Show == nil {
+			msg.episodes[i].Show = &m.show.show
+		}
+	}
+	m.show.episodes = append(m.show.episodes, msg.episodes...)
+	m.show.status = ""
+	return m.loadMoreEpisodes()
+}
+
+// loadMoreEpisodes requests the next page of episodes when the cursor is
+// within loadAhead rows of the end.
+func (m Model) loadMoreEpisodes() (Model, tea.Cmd) {
+	s := &m.show
+	if s.pager == nil || s.loading || s.pager.Done() || s.cursor < len(s.episodes)-loadAhead {
+		return m, nil
+	}
+	s.loading = true
+	return m, loadEpisodesPage(s.pager)
+}
+
+// selectedEpisode returns the highlighted episode, or nil.
+func (m Model) selectedEpisode() *webapi.Episode {
+	if m.show.cursor < 0 || m.show.cursor >= len(m.show.episodes) {
+		return nil
+	}
+	return &m.show.episodes[m.show.cursor]
+}
+
+// handleShowKey processes key events in the episode list.
+func (m Model) handleShowKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.view = m.show.back
+		return m, nil
+	case "up", "k":
+		if m.show.cursor > 0 {
+			m.show.cursor--
+		}
+	case "down", "j":
+		if m.show.cursor < len(m.show.episodes)-1 {
+			m.show.cursor++
+		}
+		return m.loadMoreEpisodes()
+	case "enter":
+		if e := m.selectedEpisode(); e != nil {
+			if why := unplayable(e.Track()); why != "" {
+				m.show.status = why
+				return m, nil
+			}
+			m.show.status = "Playing: " + e.Name
+			return m, playEpisode(m.pc, *e)
+		}
+	case "0":
+		// Start over, ignoring the resume point.
+		if e := m.selectedEpisode(); e != nil {
+			if why := unplayable(e.Track()); why != "" {
+				m.show.status = why
+				return m, nil
+			}
+			m.show.status = "Playing: " + e.Name
+			return m, playTrack(m.pc, "", e.URI, e.Name)
+		}
+	case "e", "E":
+		if e := m.selectedEpisode(); e != nil {
+			t := e.Track()
+			return m.enqueue(&t, msg.String() == "E")
+		}
+	}
+	return m, nil
+}
+
+// showView renders the episode list of a podcast.
+func (m Model) showView() string {
+	s := &m.show
+	var b strings.Builder
+	b.WriteString("\n")
+	b.WriteString(titleStyle.Render("  🎙 "+truncate(s.show.Name, 55)) + "\n")
+	if s.show.Publisher != "" {
+		b.WriteString(dimStyle.Render("  "+truncate(s.show.Publisher, 60)) + "\n")
+	}
+	b.WriteString("\n")
+
+	if s.status != "" {
+		b.WriteString(dimStyle.Render("  "+s.status) + "\n\n")
+	}
+	if len(s.episodes) == 0 {
+		b.WriteString(helpStyle.Render("  [esc] back") + "\n")
+		return b.String()
+	}
+
+	visible := max(m.height-10, 3)
+	start := 0
+	if s.cursor >= visible {
+		start = s.cursor - visible + 1
+	}
+	end := min(start+visible, len(s.episodes))
+
+	for i := start; i < end; i++ {
+		e := s.episodes[i]
+		line := episodeProgress(e) + "  " + e.ReleaseDate + "  " + truncate(e.Name, 45)
+		b.WriteString(trackRow(line, e.Track(), i == s.cursor))
+	}
+	if s.loading {
+		b.WriteString(dimStyle.Render("    loading more...") + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  [↑↓] move  [enter] play/resume  [0] play from start  [e/E] queue/next  [esc] back  (✓ played  ◐ started)") + "\n")
+	return b.String()
+}
+
+// episodeProgress is a fixed-width played marker with the time left (or the
+// length of an unstarted episode).
+func episodeProgress(e webapi.Episode) string {
+	length := time.Duration(e.Duration) * time.Millisecond
+	switch {
+	case e.ResumePoint != nil && e.ResumePoint.FullyPlayed:
+		return "✓ " + pad(display.FormatDuration(length), 7)
+	case e.Resume() > 0:
+		left := length - time.Duration(e.Resume())*time.Millisecond
+		return "◐ " + pad("-"+display.FormatDuration(left), 7)
+	}
+	return "  " + pad(display.FormatDuration(length), 7)
+}
+
+// pad right-aligns s in a field of width runes.
+func pad(s string, width int) string {
+	if n := len([]rune(s)); n < width {
+		return strings.Repeat(" ", width-n) + s
+	}
+	return s
+}
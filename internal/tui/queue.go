@@ -0,0 +1,268 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"cli_spotify/internal/player"
+	"cli_spotify/internal/webapi"
+)
+
+// queueState holds the queue view and the locally staged queue.
+//
+// Spotify's queue can only be appended to, so items queued from here are
+// staged locally and handed to the daemon one at a time, each once the
+// previous one starts playing. Until then they can still be reordered
+// ("play next" goes to the front) or cleared.
+type queueState struct {
+	staged   []webapi.Track // waiting here, in play order
+	sent     string         // URI handed to the daemon that has not started yet
+	sentAt   time.Time      // when sent was handed over
+	sentFrom string         // context playing when sent was handed over
+	upcoming []webapi.Track // Spotify's queue as last fetched
+	cursor   int            // within staged
+	status   string
+}
+
+// sentTimeout is how long a handed-over item may take to start before the
+// staged items stop waiting for it, e.g. because it was removed from
+// Spotify's queue elsewhere.
+const sentTimeout = 15 * time.Minute
+
+// queueMsg carries Spotify's view of the queue.
+type queueMsg struct {
+	queue *webapi.Queue
+	err   error
+}
+
+// queuedMsg carries the outcome of handing a staged item to the daemon.
+type queuedMsg struct {
+	track webapi.Track
+	err   error
+}
+
+// loadQueue fetches the playback queue.
+func loadQueue(web *webapi.Client) tea.Cmd {
+	return func() tea.Msg {
+		q, err := web.Queue()
+		return queueMsg{queue: q, err: err}
+	}
+}
+
+// sendToQueue appends a track to the daemon's queue.
+func sendToQueue(pc *player.Client, t webapi.Track) tea.Cmd {
+	return func() tea.Msg {
+		return queuedMsg{track: t, err: pc.AddToQueue(t.URI)}
+	}
+}
+
+// enqueue stages a track, at the front of the staged items when next is set
+// and at the end otherwise.
+func (m Model) enqueue(t *webapi.Track, next bool) (Model, tea.Cmd) {
+	if t == nil || m.pc == nil {
+		return m, nil
+	}
+	if why := unplayable(*t); why != "" {
+		m.notice = why
+		return m, nil
+	}
+	if next {
+		m.queue.staged = append([]webapi.Track{*t}, m.queue.staged...)
+		m.notice = "Playing next: " + t.Name
+	} else {
+		m.queue.staged = append(m.queue.staged, *t)
+		m.notice = "Queued: " + t.Name
+	}
+	return m.feedQueue()
+}
+
+// feedQueue hands the first staged item to the daemon unless one it was
+// given earlier is still waiting to play.
+func (m Model) feedQueue() (Model, tea.Cmd) {
+	q := &m.queue
+	if q.sent != "" || len(q.staged) == 0 || m.pc == nil {
+		return m, nil
+	}
+	t := q.staged[0]
+	q.staged = append([]webapi.Track(nil), q.staged[1:]...)
+	q.sent, q.sentAt, q.sentFrom = t.URI, time.Now(), m.pb.contextURI
+	q.cursor = min(q.cursor, max(len(q.staged)-1, 0))
+	return m, sendToQueue(m.pc, t)
+}
+
+// advanceQueue notices the sent item starting to play and feeds the next.
+// It also stops waiting for a sent item that is unlikely to play: one that
+// has not started within sentTimeout, or after another context started. While
+// the queue view is open it also refreshes Spotify's queue.
+func (m Model) advanceQueue() (Model, tea.Cmd) {
+	var reload tea.Cmd
+	if m.view == viewQueue {
+		reload = loadQueue(m.web)
+	}
+	q := &m.queue
+	if q.sent == "" {
+		return m, reload
+	}
+	started := m.pb.uri == q.sent
+	abandoned := time.Since(q.sentAt) > sentTimeout || (q.sentFrom != "" && m.pb.contextURI != q.sentFrom)
+	if !started && !abandoned {
+		return m, reload
+	}
+	q.sent = ""
+	m, feed := m.feedQueue()
+	return m, tea.Batch(feed, reload)
+}
+
+// applyQueued records the outcome of sendToQueue. A failed item goes back to
+// the front of the staged list.
+func (m Model) applyQueued(msg queuedMsg) (Model, tea.Cmd) {
+	if msg.err == nil {
+		if m.view == viewQueue {
+			return m, loadQueue(m.web)
+		}
+		return m, nil
+	}
+	if m.queue.sent == msg.track.URI {
+		m.queue.sent = ""
+	}
+	m.queue.staged = append([]webapi.Track{msg.track}, m.queue.staged...)
+	m.notice = "Queueing " + msg.track.Name + " failed: " + msg.err.Error()
+	return m, nil
+}
+
+// enterQueue switches to the queue view and fetches Spotify's queue.
+func (m Model) enterQueue() (Model, tea.Cmd) {
+	m.view = viewQueue
+	m.queue.status = "Loading queue..."
+	return m, loadQueue(m.web)
+}
+
+// applyQueue fills the upcoming list.
+func (m Model) applyQueue(msg queueMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m.queue.status = "Error: " + msg.err.Error()
+		return m, nil
+	}
+	m.queue.upcoming = msg.queue.Queue
+	m.queue.status = ""
+	return m, nil
+}
+
+// handleQueueKey processes key events in the queue view. The cursor moves
+// over the staged items, the only part of the queue that can be edited.
+func (m Model) handleQueueKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	q := &m.queue
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.view = viewNowPlaying
+		return m, nil
+	case "up", "k":
+		if q.cursor > 0 {
+			q.cursor--
+		}
+	case "down", "j":
+		if q.cursor < len(q.staged)-1 {
+			q.cursor++
+		}
+	case "K", "J":
+		j := q.cursor - 1
+		if msg.String() == "J" {
+			j = q.cursor + 1
+		}
+		if q.cursor < len(q.staged) && j >= 0 && j < len(q.staged) {
+			q.staged[q.cursor], q.staged[j] = q.staged[j], q.staged[q.cursor]
+			q.cursor = j
+		}
+	case "x":
+		if q.cursor < len(q.staged) {
+			q.staged = append(q.staged[:q.cursor:q.cursor], q.staged[q.cursor+1:]...)
+			q.cursor = min(q.cursor, max(len(q.staged)-1, 0))
+		}
+	case "c":
+		q.staged = nil
+		q.sent = ""
+		q.cursor = 0
+		m.notice = "Cleared staged items. Tracks already in Spotify's queue stay there."
+	case "r":
+		return m.enterQueue()
+	}
+	return m, nil
+}
+
+// queueView renders the queue screen: what is playing and from where, the
+// staged items, then Spotify's own queue.
+func (m Model) queueView() string {
+	q := &m.queue
+	var b strings.Builder
+	b.WriteString("\n")
+	b.WriteString(titleStyle.Render("  ♪ QUEUE") + "\n\n")
+
+	if m.pb.stopped || m.pb.trackName == "" {
+		b.WriteString(dimStyle.Render("  Nothing playing.") + "\n")
+	} else {
+		b.WriteString("  " + trackStyle.Render(truncate(m.pb.trackName, 45)) + dimStyle.Render(dimSep+truncate(m.pb.artists, 30)) + "\n")
+	}
+	if ctx := describeContext(m.pb.contextURI); ctx != "" {
+		b.WriteString(dimStyle.Render("  Playing from "+ctx) + "\n")
+	}
+	b.WriteString("\n")
+
+	if q.status != "" {
+		b.WriteString(dimStyle.Render("  "+q.status) + "\n\n")
+	}
+
+	b.WriteString(yellowStyle.Render("  Staged here") + "\n")
+	if len(q.staged) == 0 {
+		b.WriteString(dimStyle.Render("    (none — [e] queue or [E] play next from any track list)") + "\n")
+	}
+	for i, t := range q.staged {
+		line := truncate(t.Name, 40) + dimSep + truncate(t.ArtistNames(), 25)
+		if i == q.cursor {
+			b.WriteString(greenStyle.Render("  ▸ "+line) + "\n")
+		} else {
+			b.WriteString("    " + dimStyle.Render(line) + "\n")
+		}
+	}
+	b.WriteString("\n")
+
+	b.WriteString(yellowStyle.Render("  Up next on Spotify") + "\n")
+	visible := max(m.height-14-len(q.staged), 3)
+	for i, t := range q.upcoming {
+		if i == visible {
+			b.WriteString(dimStyle.Render("    ...") + "\n")
+			break
+		}
+		line := truncate(t.Name, 40)
+		if names := t.ArtistNames(); names != "" {
+			line += dimSep + truncate(names, 25)
+		}
+		if t.URI == q.sent {
+			line += "  (from here)"
+		}
+		b.WriteString("    " + dimStyle.Render(line) + "\n")
+	}
+	if len(q.upcoming) == 0 && q.status == "" {
+		b.WriteString(dimStyle.Render("    (empty)") + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  [↑↓] move  [J/K] reorder  [x] remove  [c] clear staged  [r] refresh  [esc] back") + "\n")
+	return b.String()
+}
+
+// describeContext turns a context URI into "playlist 37i9dQZF1DX..." style
+// text, or "" when there is none.
+func describeContext(uri string) string {
+	parts := strings.Split(uri, ":")
+	if len(parts) < 3 || parts[0] != "spotify" {
+		return ""
+	}
+	if parts[1] == "user" && len(parts) >= 4 && parts[3] == "collection" {
+		return "Liked Songs"
+	}
+	return parts[1] + " " + parts[len(parts)-1]
+}
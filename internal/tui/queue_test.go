@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"cli_spotify/internal/player"
+	"cli_spotify/internal/webapi"
+)
+
+func track(id string) *webapi.Track {
+	return &webapi.Track{URI: "spotify:track:" + id, Name: id}
+}
+
+// stagedTwo returns a model that has handed a to the daemon, with b staged
+// behind it, while playing from an album.
+func stagedTwo(t *testing.T) Model {
+	t.Helper()
+	pc := failingDaemon(t, `{}`)
+	m := New(pc, nil, nil, &player.Status{ContextURI: "spotify:album:x"}, nil)
+	m, _ = m.enqueue(track("a"), false)
+	m, _ = m.enqueue(track("b"), false)
+	if m.queue.sent != "spotify:track:a" || len(m.queue.staged) != 1 {
+		t.Fatalf("sent %q with %d staged, want a with b staged", m.queue.sent, len(m.queue.staged))
+	}
+	return m
+}
+
+func TestClearForgetsSentItem(t *testing.T) {
+	m := stagedTwo(t)
+	next, _ := m.handleQueueKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	m = next.(Model)
+	m, cmd := m.enqueue(track("c"), false)
+	if cmd == nil || m.queue.sent != "spotify:track:c" {
+		t.Errorf("after clearing, the next item waits for %q", m.queue.sent)
+	}
+}
+
+func TestQueueStopsWaitingForSentItem(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*Model)
+		feeds  bool
+	}{
+		{name: "another track of the context", change: func(m *Model) { m.pb.uri = "spotify:track:other" }},
+		{name: "sent item starts", change: func(m *Model) { m.pb.uri = "spotify:track:a" }, feeds: true},
+		{name: "context changes", change: func(m *Model) { m.pb.contextURI = "spotify:playlist:y" }, feeds: true},
+		{name: "timeout", change: func(m *Model) { m.queue.sentAt = time.Now().Add(-sentTimeout - time.Second) }, feeds: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := stagedTwo(t)
+			tt.change(&m)
+			m, _ = m.advanceQueue()
+			want := "spotify:track:a"
+			if tt.feeds {
+				want = "spotify:track:b"
+			}
+			if m.queue.sent != want {
+				t.Errorf("sent = %q, want %q", m.queue.sent, want)
+			}
+		})
+	}
+}
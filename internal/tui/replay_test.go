@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"cli_spotify/internal/player"
+)
+
+// replaySession feeds a recorded session through Model.Update, one
+// playerEventMsg per entry, and calls check after each with the entry's index.
+func replaySession(t *testing.T, path string, check func(i int, ev player.Event, m Model)) {
+	t.Helper()
+	entries, err := player.LoadSession(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := player.NewReplayer(entries, 0)
+	r.Start()
+	defer r.Close()
+
+	m := New(nil, nil, r.Ch, nil, nil)
+	for i := range entries {
+		var ev player.Event
+		select {
+		case ev = <-r.Ch:
+		case <-time.After(time.Second):
+			t.Fatalf("replay stalled after %d of %d entries", i, len(entries))
+		}
+		next, _ := m.Update(playerEventMsg{ev: ev, ok: true})
+		m = next.(Model)
+		check(i, ev, m)
+	}
+}
+
+func TestReplaySession(t *testing.T) {
+	type want struct {
+		uri, name, artists string
+		playing, stopped   bool
+		buffering          bool
+		volume             int
+		shuffle            bool
+		repeat             string
+		pos                time.Duration // checked when non-zero
+	}
+	wants := []want{
+		// Initial status: paused on the first track.
+		{uri: "spotify:track:a1t1", name: "Opening", artists: "Artist One", volume: 40, repeat: "off", pos: 61 * time.Second},
+		// will_play: loading the next track.
+		{uri: "spotify:track:a1t1", name: "Opening", artists: "Artist One", buffering: true, volume: 40, repeat: "off"},
+		// metadata for the new track.
+		{uri: "spotify:track:a1t2", name: "Closing", artists: "Artist One, Guest", buffering: true, volume: 40, repeat: "off"},
+		{uri: "spotify:track:a1t2", name: "Closing", artists: "Artist One, Guest", playing: true, volume: 40, repeat: "off"},
+		{uri: "spotify:track:a1t2", name: "Closing", artists: "Artist One, Guest", playing: true, volume: 55, repeat: "off"},
+		{uri: "spotify:track:a1t2", name: "Closing", artists: "Artist One, Guest", playing: true, volume: 55, repeat: "off", pos: 90 * time.Second},
+		{uri: "spotify:track:a1t2", name: "Closing", artists: "Artist One, Guest", playing: true, volume: 55, shuffle: true, repeat: "off"},
+		{uri: "spotify:track:a1t2", name: "Closing", artists: "Artist One, Guest", playing: true, volume: 55, shuffle: true, repeat: "context"},
+		{uri: "spotify:track:a1t2", name: "Closing", artists: "Artist One, Guest", playing: true, volume: 55, shuffle: true, repeat: "track"},
+		// Turning track repeat off leaves repeat off, not context.
+		{uri: "spotify:track:a1t2", name: "Closing", artists: "Artist One, Guest", playing: true, volume: 55, shuffle: true, repeat: "off"},
+		{uri: "spotify:track:a1t2", name: "Closing", artists: "Artist One, Guest", volume: 55, shuffle: true, repeat: "off"},
+		// not_playing is idle, not stopped.
+		{uri: "spotify:track:a1t2", name: "Closing", artists: "Artist One, Guest", volume: 55, shuffle: true, repeat: "off"},
+		{uri: "spotify:track:a1t2", name: "Closing", artists: "Artist One, Guest", stopped: true, volume: 55, shuffle: true, repeat: "off"},
+	}
+
+	n := 0
+	replaySession(t, "testdata/session.jsonl", func(i int, ev player.Event, m Model) {
+		n++
+		if i >= len(wants) {
+			t.Fatalf("unexpected entry %d (%s)", i, ev.Type)
+		}
+		w, pb := wants[i], m.pb
+		got := want{
+			uri: pb.uri, name: pb.trackName, artists: pb.artists,
+			playing: pb.isPlaying, stopped: pb.stopped, buffering: pb.buffering,
+			volume: pb.volume, shuffle: pb.shuffle, repeat: pb.repeat, pos: w.pos,
+		}
+		if got != w {
+			t.Errorf("after entry %d (%s):\n got %+v\nwant %+v", i, ev.Type, got, w)
+		}
+		if w.pos != 0 {
+			// The clock may have advanced a little since the entry was applied.
+			if at := pb.pos.At(time.Now()); at < w.pos || at > w.pos+time.Second {
+				t.Errorf("after entry %d (%s): position %v, want %v", i, ev.Type, at, w.pos)
+			}
+		}
+		if pb.pos.Running() != (pb.isPlaying && !pb.buffering && !pb.stopped) {
+			t.Errorf("after entry %d (%s): clock running = %v", i, ev.Type, pb.pos.Running())
+		}
+	})
+	if n != len(wants) {
+		t.Errorf("replayed %d entries, want %d", n, len(wants))
+	}
+}
@@ -1,29 +1,184 @@
 package tui
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
+	"cli_spotify/internal/library"
 	"cli_spotify/internal/webapi"
 )
 
+// searchLibrary is the pseudo search type of the Library tab, whose results
+// come from the local library mirror instead of GET /search.
+const searchLibrary webapi.SearchType = "library"
+
+// librarySearchLimit caps the Library tab's results. They are all computed
+// at once, so there is no paging.
+const librarySearchLimit = 200
+
+// searchTabs are the result sections of the search screen, one per type.
+var searchTabs = []struct {
+	kind  webapi.SearchType
+	title string
+}{
+	{webapi.SearchTrack, "Tracks"},
+	{webapi.SearchAlbum, "Albums"},
+	{webapi.SearchArtist, "Artists"},
+	{webapi.SearchPlaylist, "Playlists"},
+	{webapi.SearchShow, "Shows"},
+	{webapi.SearchEpisode, "Episodes"},
+	{searchLibrary, "Library"},
+}
+
+// searchLoadAhead is how close the cursor may get to the end of a result tab
+// before the next page is requested. Search pages are small, so it is lower
+// than the library's loadAhead.
+const searchLoadAhead = 3
+
 // searchState holds the search view's input, results, and selection.
 type searchState struct {
 	input   textinput.Model
-	results []webapi.Track
-	cursor  int
-	typing  bool   // true while the query field is focused
-	status  string // transient status/error line
+	query   string // query the results belong to
+	results webapi.SearchResults
+	local   []library.Result // matches in the library mirror
+	tab     int              // index into searchTabs
+	cursors []int            // cursor per tab
+	loading []bool           // a further page is being fetched, per tab
+	typing  bool             // true while the query field is focused
+	status  string           // transient status/error line
 }
 
 func newSearchState() searchState {
 	ti := textinput.New()
-	ti.Placeholder = "search songs..."
+	ti.Placeholder = "search songs, albums, artists, playlists, podcasts..."
 	ti.Prompt = "  🔎 "
 	ti.CharLimit = 100
-	return searchState{input: ti}
+	return searchState{
+		input:   ti,
+		cursors: make([]int, len(searchTabs)),
+		loading: make([]bool, len(searchTabs)),
+	}
+}
+
+// paging returns the API-reported total, whether more results exist and the
+// offset of the next page for the given tab.
+func (s *searchState) paging(tab int) (total int, more bool, next int) {
+	r := &s.results
+	switch searchTabs[tab].kind {
+	case webapi.SearchTrack:
+		return r.Tracks.Total, r.Tracks.More(), r.Tracks.NextOffset()
+	case webapi.SearchAlbum:
+		return r.Albums.Total, r.Albums.More(), r.Albums.NextOffset()
+	case webapi.SearchArtist:
+		return r.Artists.Total, r.Artists.More(), r.Artists.NextOffset()
+	case webapi.SearchPlaylist:
+		return r.Playlists.Total, r.Playlists.More(), r.Playlists.NextOffset()
+	case webapi.SearchShow:
+		return r.Shows.Total, r.Shows.More(), r.Shows.NextOffset()
+	case webapi.SearchEpisode:
+		return r.Episodes.Total, r.Episodes.More(), r.Episodes.NextOffset()
+	}
+	return 0, false, 0
+}
+
+// appendPage adds a further page of the given tab's type to the results.
+func (s *searchState) appendPage(tab int, next *webapi.SearchResults) {
+	r := &s.results
+	switch searchTabs[tab].kind {
+	case webapi.SearchTrack:
+		r.Tracks.Append(next.Tracks)
+	case webapi.SearchAlbum:
+		r.Albums.Append(next.Albums)
+	case webapi.SearchArtist:
+		r.Artists.Append(next.Artists)
+	case webapi.SearchPlaylist:
+		r.Playlists.Append(next.Playlists)
+	case webapi.SearchShow:
+		r.Shows.Append(next.Shows)
+	case webapi.SearchEpisode:
+		r.Episodes.Append(next.Episodes)
+	}
+}
+
+// count returns the number of results in the given tab.
+func (s *searchState) count(tab int) int {
+	r := &s.results
+	switch searchTabs[tab].kind {
+	case webapi.SearchTrack:
+		return len(r.Tracks.Items)
+	case webapi.SearchAlbum:
+		return len(r.Albums.Items)
+	case webapi.SearchArtist:
+		return len(r.Artists.Items)
+	case webapi.SearchPlaylist:
+		return len(r.Playlists.Items)
+	case webapi.SearchShow:
+		return len(r.Shows.Items)
+	case webapi.SearchEpisode:
+		return len(r.Episodes.Items)
+	case searchLibrary:
+		return len(s.local)
+	}
+	return 0
+}
+
+// row renders result i of the given tab as a list line.
+func (s *searchState) row(tab, i int) string {
+	r := &s.results
+	switch searchTabs[tab].kind {
+	case webapi.SearchTrack:
+		t := r.Tracks.Items[i]
+		return truncate(t.Name, 45) + dimSep + truncate(t.ArtistNames(), 30)
+	case webapi.SearchAlbum:
+		a := r.Albums.Items[i]
+		return truncate(a.Name, 45) + dimSep + truncate(a.ArtistNames(), 30)
+	case webapi.SearchArtist:
+		return truncate(r.Artists.Items[i].Name, 70)
+	case webapi.SearchPlaylist:
+		p := r.Playlists.Items[i]
+		return truncate(p.Name, 45) + dimSep + truncate(p.Owner.DisplayName, 30)
+	case webapi.SearchShow:
+		sh := r.Shows.Items[i]
+		return truncate(sh.Name, 45) + dimSep + truncate(sh.Publisher, 30)
+	case webapi.SearchEpisode:
+		e := r.Episodes.Items[i]
+		return truncate(e.Name, 55) + dimSep + e.ReleaseDate
+	case searchLibrary:
+		l := s.local[i]
+		return fmt.Sprintf("%-9s", l.Kind) + truncate(l.Name, 40) + dimSep + truncate(l.Detail, 30)
+	}
+	return ""
+}
+
+// selectedTrack returns the highlighted track on the Tracks tab or a track
+// on the Library tab, or nil.
+func (s *searchState) selectedTrack() *webapi.Track {
+	return s.trackAt(s.cursors[s.tab])
+}
+
+// trackAt returns the track in row i of the active tab, or nil if
+// the row is not a track.
+func (s *searchState) trackAt(i int) *webapi.Track {
+	switch searchTabs[s.tab].kind {
+	case webapi.SearchTrack:
+		if i < len(s.results.Tracks.Items) {
+			return &s.results.Tracks.Items[i]
+		}
+	case searchLibrary:
+		if i < len(s.local) && s.local[i].Kind == library.KindTrack {
+			return s.local[i].Track
+		}
+	}
+	return nil
+}
+
+// libraryTab returns the index of the Library tab.
+func libraryTab() int {
+	return len(searchTabs) - 1
 }
 
 // enterSearch focuses the query field and switches to the search view.
@@ -34,6 +189,72 @@ func (m Model) enterSearch() (Model, tea.Cmd) {
 	return m, cmd
 }
 
+// applySearchResults shows a finished search, opening the first tab that has
+// results.
+func (m Model) applySearchResults(msg searchResultsMsg) (Model, tea.Cmd) {
+	if msg.err != nil && len(m.search.local) == 0 {
+		m.search.status = "Search failed: " + msg.err.Error()
+		return m, nil
+	}
+	m.search.query = msg.query
+	m.search.cursors = make([]int, len(searchTabs))
+	m.search.loading = make([]bool, len(searchTabs))
+	if msg.err != nil {
+		// Offline or failing: the library matches still stand.
+		m.search.results = webapi.SearchResults{}
+		m.search.tab = libraryTab()
+		m.search.status = "Spotify search failed, showing library matches: " + msg.err.Error()
+		return m, nil
+	}
+	m.search.results = *msg.results
+	m.search.tab = 0
+	for i := range searchTabs {
+		if m.search.count(i) > 0 {
+			m.search.tab = i
+			break
+		}
+	}
+	if m.search.count(m.search.tab) == 0 {
+		m.search.status = "No results."
+	} else {
+		m.search.status = ""
+	}
+	uris := trackURIs(msg.results.Tracks.Items)
+	for _, r := range m.search.local {
+		if r.Kind == library.KindTrack {
+			uris = append(uris, r.Track.LibraryURI())
+		}
+	}
+	return m, m.refreshSaved(uris...)
+}
+
+// applySearchMore appends a further page of one result type.
+func (m Model) applySearchMore(msg searchMoreMsg) (Model, tea.Cmd) {
+	if msg.query != m.search.query {
+		return m, nil // a newer search has replaced these results
+	}
+	m.search.loading[msg.tab] = false
+	if msg.err != nil {
+		m.search.status = "Loading more failed: " + msg.err.Error()
+		return m, nil
+	}
+	m.search.appendPage(msg.tab, msg.results)
+	m, more := m.loadMoreSearch()
+	return m, tea.Batch(more, m.refreshSaved(trackURIs(msg.results.Tracks.Items)...))
+}
+
+// loadMoreSearch requests the next page of the active tab when the cursor is
+// close to the end of the results loaded so far.
+func (m Model) loadMoreSearch() (Model, tea.Cmd) {
+	tab := m.search.tab
+	_, more, next := m.search.paging(tab)
+	if !more || m.search.loading[tab] || m.search.cursors[tab] < m.search.count(tab)-searchLoadAhead {
+		return m, nil
+	}
+	m.search.loading[tab] = true
+	return m, doSearchMore(m.web, m.search.query, tab, next)
+}
+
 // handleSearchKey routes keys while the search view is active.
 func (m Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
@@ -43,6 +264,12 @@ func (m Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.search.input.Blur()
 		m.view = viewNowPlaying
 		return m, nil
+	case "tab":
+		m.search.tab = (m.search.tab + 1) % len(searchTabs)
+		return m, nil
+	case "shift+tab":
+		m.search.tab = (m.search.tab + len(searchTabs) - 1) % len(searchTabs)
+		return m, nil
 	}
 
 	if m.search.typing {
@@ -55,6 +282,18 @@ func (m Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.search.typing = false
 			m.search.input.Blur()
 			m.search.status = "Searching..."
+			m.search.local = nil
+			if m.sync != nil {
+				// Library matches show at once, while Spotify is searched.
+				m.search.local = m.sync.Mirror().Search(query, librarySearchLimit)
+			}
+			if len(m.search.local) > 0 {
+				m.search.query = query
+				m.search.results = webapi.SearchResults{}
+				m.search.cursors = make([]int, len(searchTabs))
+				m.search.tab = libraryTab()
+				m.search.status = "Searching Spotify..."
+			}
 			return m, doSearch(m.web, query)
 		}
 		var cmd tea.Cmd
@@ -63,33 +302,110 @@ func (m Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 
 	// Results list is focused.
+	cur := &m.search.cursors[m.search.tab]
 	switch msg.String() {
 	case "up", "k":
-		if m.search.cursor > 0 {
-			m.search.cursor--
+		if *cur > 0 {
+			*cur--
 		}
 	case "down", "j":
-		if m.search.cursor < len(m.search.results)-1 {
-			m.search.cursor++
+		if *cur < m.search.count(m.search.tab)-1 {
+			*cur++
 		}
+		return m.loadMoreSearch()
+	case "left", "h":
+		m.search.tab = (m.search.tab + len(searchTabs) - 1) % len(searchTabs)
+	case "right", "l":
+		m.search.tab = (m.search.tab + 1) % len(searchTabs)
 	case "/", "i":
 		m.search.typing = true
 		return m, m.search.input.Focus()
 	case "enter":
-		if t := m.selectedTrack(); t != nil {
-			m.search.status = "Playing: " + t.Name
-			return m, playTrack(m.pc, "", t.URI, t.Name)
+		return m.openSearchResult()
+	case "a":
+		if t := m.search.selectedTrack(); t != nil {
+			return m.openTrackArtist(t, viewSearch)
+		}
+	case "+":
+		if t := m.search.selectedTrack(); t != nil {
+			return m.openPicker(t.LibraryURI(), t.Name)
 		}
+	case "f":
+		if t := m.search.selectedTrack(); t != nil {
+			return m.toggleLiked(t.LibraryURI())
+		}
+	case "e", "E":
+		return m.enqueue(m.search.selectedTrack(), msg.String() == "E")
 	}
 	return m, nil
 }
 
-// selectedTrack returns the highlighted result, or nil.
-func (m Model) selectedTrack() *webapi.Track {
-	if m.search.cursor < 0 || m.search.cursor >= len(m.search.results) {
-		return nil
+// openSearchResult acts on the highlighted result: tracks and episodes play,
+// albums and playlists open their track list, artists open their page and
+// shows open their episode list.
+func (m Model) openSearchResult() (Model, tea.Cmd) {
+	i := m.search.cursors[m.search.tab]
+	if i < 0 || i >= m.search.count(m.search.tab) {
+		return m, nil
 	}
-	return &m.search.results[m.search.cursor]
+	r := &m.search.results
+	switch searchTabs[m.search.tab].kind {
+	case webapi.SearchTrack:
+		t := r.Tracks.Items[i]
+		if why := unplayable(t); why != "" {
+			m.search.status = why
+			return m, nil
+		}
+		m.search.status = "Playing: " + t.Name
+		return m, playTrack(m.pc, "", t.URI, t.Name)
+	case webapi.SearchAlbum:
+		a := r.Albums.Items[i]
+		m.playlist = playlistState{name: a.Name, uri: a.URI}
+		return m.openTrackList(m.web.AlbumTracksPager(a.ID), viewSearch)
+	case webapi.SearchArtist:
+		a := r.Artists.Items[i]
+		return m.openArtist(a.URI, a.Name, viewSearch)
+	case webapi.SearchPlaylist:
+		p := r.Playlists.Items[i]
+		m.playlist = playlistState{name: p.Name, uri: p.URI, editable: m.editable(p), snapshot: p.SnapshotID}
+		return m.openTrackList(m.web.PlaylistTracksPager(p.URI), viewSearch)
+	case webapi.SearchShow:
+		return m.openShow(r.Shows.Items[i], viewSearch)
+	case webapi.SearchEpisode:
+		e := r.Episodes.Items[i]
+		m.search.status = "Playing: " + e.Name
+		return m, playEpisode(m.pc, e)
+	case searchLibrary:
+		return m.openLibraryResult(m.search.local[i])
+	}
+	return m, nil
+}
+
+// openLibraryResult acts on a Library tab result like openSearchResult does
+// on Spotify's, reading playlist items from the mirror when it has them.
+func (m Model) openLibraryResult(r library.Result) (Model, tea.Cmd) {
+	switch r.Kind {
+	case library.KindTrack:
+		if why := unplayable(*r.Track); why != "" {
+			m.search.status = why
+			return m, nil
+		}
+		m.search.status = "Playing: " + r.Name
+		return m, playTrack(m.pc, "", r.URI, r.Name)
+	case library.KindAlbum:
+		m.playlist = playlistState{name: r.Name, uri: r.URI}
+		return m.openTrackList(m.web.AlbumTracksPager(r.Album.ID), viewSearch)
+	case library.KindArtist:
+		return m.openArtist(r.URI, r.Name, viewSearch)
+	case library.KindPlaylist:
+		p := *r.Playlist
+		m.playlist = playlistState{name: p.Name, uri: p.URI, editable: m.editable(p), snapshot: p.SnapshotID}
+		if tracks, total, ok := m.sync.Mirror().PlaylistItems(p.URI); ok {
+			return m.openTrackList(webapi.StaticPager(tracks, total), viewSearch)
+		}
+		return m.openTrackList(m.web.PlaylistTracksPager(p.URI), viewSearch)
+	}
+	return m, nil
 }
 
 // searchView renders the search screen.
@@ -99,41 +415,70 @@ func (m Model) searchView() string {
 	b.WriteString(titleStyle.Render("  🔎 SEARCH") + "\n\n")
 	b.WriteString(m.search.input.View() + "\n\n")
 
+	// Tab bar with per-type result counts.
+	tabs := make([]string, len(searchTabs))
+	for i, t := range searchTabs {
+		label := t.title + " " + strconv.Itoa(m.search.count(i))
+		if total, more, _ := m.search.paging(i); more {
+			label += "/" + strconv.Itoa(total)
+		}
+		if i == m.search.tab {
+			tabs[i] = greenStyle.Render("[" + label + "]")
+		} else {
+			tabs[i] = dimStyle.Render(" " + label + " ")
+		}
+	}
+	b.WriteString("  " + strings.Join(tabs, " ") + "\n\n")
+
 	if m.search.status != "" {
 		b.WriteString(dimStyle.Render("  "+m.search.status) + "\n\n")
 	}
 
-	if len(m.search.results) == 0 {
-		b.WriteString(helpStyle.Render("  Type a query and press Enter. [esc] back") + "\n")
+	n := m.search.count(m.search.tab)
+	if n == 0 {
+		b.WriteString(helpStyle.Render("  Type a query and press Enter. [tab] switch type  [esc] back") + "\n")
 		return b.String()
 	}
 
 	// Scroll a window of results around the cursor.
-	visible := m.height - 9
+	cur := m.search.cursors[m.search.tab]
+	visible := m.height - 11
 	if visible < 3 {
 		visible = 3
 	}
 	start := 0
-	if m.search.cursor >= visible {
-		start = m.search.cursor - visible + 1
+	if cur >= visible {
+		start = cur - visible + 1
 	}
 	end := start + visible
-	if end > len(m.search.results) {
-		end = len(m.search.results)
+	if end > n {
+		end = n
 	}
 
 	for i := start; i < end; i++ {
-		t := m.search.results[i]
-		line := truncate(t.Name, 45) + dimSep + truncate(t.ArtistNames(), 30)
-		if i == m.search.cursor {
+		line := m.search.row(m.search.tab, i)
+		switch t := m.search.trackAt(i); {
+		case t != nil:
+			b.WriteString(trackRow(m.heart(t.LibraryURI())+line, *t, i == cur))
+			continue
+		case searchTabs[m.search.tab].kind == searchLibrary:
+			line = "  " + line // align with the hearts of track rows
+		}
+		if i == cur {
 			b.WriteString(greenStyle.Render("  ▸ "+line) + "\n")
 		} else {
 			b.WriteString("    " + dimStyle.Render(line) + "\n")
 		}
 	}
 
+	if m.search.loading[m.search.tab] {
+		b.WriteString(dimStyle.Render("    loading more...") + "\n")
+	} else if total, _, _ := m.search.paging(m.search.tab); total > 0 {
+		b.WriteString(dimStyle.Render("    "+strconv.Itoa(n)+" of "+strconv.Itoa(total)+" results") + "\n")
+	}
+
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("  [↑↓] move  [enter] play  [/] new search  [esc] back") + "\n")
+	b.WriteString(helpStyle.Render("  [↑↓] move  [←→/tab] type  [enter] play/open  [a] artist  [f] like  [e/E] queue/next  [+] add to playlist  [/] new search  [esc] back") + "\n")
 	return b.String()
 }
 
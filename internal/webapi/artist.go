@@ -0,0 +1,96 @@
+package webapi
+
+import "net/url"
+
+// Discography is an artist's releases grouped the way Spotify presents them.
+type Discography struct {
+	Albums       []Album
+	Singles      []Album
+	Compilations []Album
+}
+
+// Artist fetches the full artist object (GET /artists/{id}). artistURI may be
+// a Spotify URI or a bare ID.
+func (c *Client) Artist(artistURI string) (*Artist, error) {
+	var a Artist
+	if err := c.get("/artists/"+uriID(artistURI), nil, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ArtistTopTracks returns the artist's most popular tracks in the user's
+// market (GET /artists/{id}/top-tracks).
+func (c *Client) ArtistTopTracks(artistURI string) ([]Track, error) {
+	q := fromToken()
+	var resp struct {
+		Tracks []Track `json:"tracks"`
+	}
+	if err := c.get("/artists/"+uriID(artistURI)+"/top-tracks", q, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tracks, nil
+}
+
+// ArtistAlbums returns the artist's own releases (GET /artists/{id}/albums),
+// following every page and grouping them into albums, singles (which include
+// EPs) and compilations. Releases the artist only appears on are excluded.
+func (c *Client) ArtistAlbums(artistURI string) (*Discography, error) {
+	q := url.Values{"include_groups": {"album,single,compilation"}}
+	albums, err := newPager(c, "/artists/"+uriID(artistURI)+"/albums", q, 50, func(a Album) (Album, bool) {
+		return a, a.ID != ""
+	}).All()
+	if err != nil {
+		return nil, err
+	}
+
+	var d Discography
+	for _, a := range albums {
+		group := a.AlbumGroup
+		if group == "" {
+			group = a.AlbumType
+		}
+		switch group {
+		case "single":
+			d.Singles = append(d.Singles, a)
+		case "compilation":
+			d.Compilations = append(d.Compilations, a)
+		default:
+			d.Albums = append(d.Albums, a)
+		}
+	}
+	return &d, nil
+}
+
+// Tracks looks up tracks in batches (GET /tracks?ids=...), so long lists take
+// one request per 50 tracks. Tracks Spotify does not know are left out.
+func (c *Client) Tracks(trackURIs []string) ([]Track, error) {
+	var out []Track
+	err := c.eachIDBatch(trackURIs, func(ids string) error {
+		q := fromToken()
+		q.Set("ids", ids)
+		var resp struct {
+			Tracks []*Track `json:"tracks"`
+		}
+		if err := c.get("/tracks", q, &resp); err != nil {
+			return err
+		}
+		for _, t := range resp.Tracks {
+			if t != nil && t.URI != "" {
+				out = append(out, *t)
+			}
+		}
+		return nil
+	})
+	return out, err
+}
+
+// Track fetches a single track (GET /tracks/{id}). trackURI may be a Spotify
+// URI or a bare ID.
+func (c *Client) Track(trackURI string) (*Track, error) {
+	var t Track
+	if err := c.get("/tracks/"+uriID(trackURI), fromToken(), &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -11,16 +11,16 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
 	"time"
 )
 
 const (
-	authorizeURL = "https://accounts.spotify.com/authorize"
-	tokenURL     = "https://accounts.spotify.com/api/token"
-
-	// scopes requested for search, playback control, and library/playlist reads.
-	scopes = "user-read-playback-state user-modify-playback-state user-read-currently-playing playlist-read-private playlist-read-collaborative user-library-read user-read-private"
+	// scopes requested for search, playback control and state (devices,
+	// queue), library/playlist reads, playlist editing, liking tracks and
+	// listening history and podcast resume points.
+	scopes = "user-read-playback-state user-modify-playback-state user-read-currently-playing playlist-read-private playlist-read-collaborative playlist-modify-public playlist-modify-private user-library-read user-library-modify user-read-recently-played user-top-read user-read-playback-position user-read-private"
 )
 
 // Token holds the OAuth tokens and their expiry. It is persisted to disk so the
@@ -36,44 +36,54 @@ func (t *Token) valid() bool {
 	return t != nil && t.AccessToken != "" && time.Now().Before(t.Expiry)
 }
 
+// coversScopes reports whether the token was granted every scope the client
+// requests. Tokens saved before a feature added a scope do not, and need a
+// fresh login.
+func (t *Token) coversScopes() bool {
+	granted := strings.Fields(t.Scope)
+	for _, want := range strings.Fields(scopes) {
+		if !slices.Contains(granted, want) {
+			return false
+		}
+	}
+	return true
+}
+
 // Authenticator runs the Authorization Code + PKCE flow and persists the token.
 type Authenticator struct {
 	clientID    string
 	redirectURI string
-	tokenPath   string
+	store       TokenStore
+	endpoints   Endpoints
 	http        *http.Client
 }
 
-// NewAuthenticator creates an Authenticator. tokenPath is where the token JSON
-// is stored (e.g. ~/.spotify-cli/webapi-token.json).
-func NewAuthenticator(clientID, redirectURI, tokenPath string) *Authenticator {
+// NewAuthenticator creates an Authenticator that persists tokens in store.
+func NewAuthenticator(clientID, redirectURI string, store TokenStore) *Authenticator {
 	return &Authenticator{
 		clientID:    clientID,
 		redirectURI: redirectURI,
-		tokenPath:   tokenPath,
+		store:       store,
+		endpoints:   DefaultEndpoints,
 		http:        &http.Client{Timeout: 15 * time.Second},
 	}
 }
 
+// UseEndpoints points the Authenticator, and any Client created from it, at
+// other servers. It must be called before NewClient.
+func (a *Authenticator) UseEndpoints(e Endpoints) {
+	a.endpoints = e
+}
+
 // LoadToken reads a previously saved token, or returns nil if none exists.
-func (a *Authenticator) LoadToken() *Token {
-	data, err := os.ReadFile(a.tokenPath)
-	if err != nil {
-		return nil
-	}
-	var t Token
-	if err := json.Unmarshal(data, &t); err != nil {
-		return nil
-	}
-	return &t
+// An unreadable store (e.g. a wrong passphrase) is an error rather than
+// "no token", so it is not silently replaced by a fresh login.
+func (a *Authenticator) LoadToken() (*Token, error) {
+	return a.store.Load()
 }
 
 func (a *Authenticator) saveToken(t *Token) error {
-	data, err := json.MarshalIndent(t, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(a.tokenPath, data, 0600)
+	return a.store.Save(t)
 }
 
 // Login runs the full interactive authorization flow and returns a fresh token.
@@ -173,7 +183,7 @@ func (a *Authenticator) authorizeURL(challenge, state string) string {
 		"code_challenge":        {challenge},
 		"state":                 {state},
 	}
-	return authorizeURL + "?" + q.Encode()
+	return a.endpoints.Authorize + "?" + q.Encode()
 }
 
 func (a *Authenticator) exchange(code, verifier string) (*Token, error) {
@@ -190,7 +200,7 @@ func (a *Authenticator) exchange(code, verifier string) (*Token, error) {
 // postToken posts an x-www-form-urlencoded request to the token endpoint and
 // decodes the response into a Token (computing Expiry from expires_in).
 func (a *Authenticator) postToken(form url.Values) (*Token, error) {
-	resp, err := a.http.PostForm(tokenURL, form)
+	resp, err := a.http.PostForm(a.endpoints.Token, form)
 	if err != nil {
 		return nil, fmt.Errorf("token request: %w", err)
 	}
@@ -0,0 +1,62 @@
+package webapi
+
+import (
+	"path/filepath"
+	"testing"
+
+	"cli_spotify/internal/webapi/webapitest"
+)
+
+func newTestAuth(t *testing.T, srv *webapitest.Server) *Authenticator {
+	t.Helper()
+	store := NewFileStore(filepath.Join(t.TempDir(), "token.json"))
+	auth := NewAuthenticator(webapitest.ClientID, "http://127.0.0.1:8888/callback", store)
+	auth.UseEndpoints(Endpoints{API: srv.APIBase(), Authorize: srv.AuthorizeURL(), Token: srv.TokenURL()})
+	return auth
+}
+
+func TestExchangeAndRefresh(t *testing.T) {
+	srv := webapitest.New()
+	defer srv.Close()
+	auth := newTestAuth(t, srv)
+
+	verifier, err := randomString(64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := srv.Authorize(s256Challenge(verifier), scopes)
+	if _, err := auth.exchange(code, "wrong verifier"); err == nil {
+		t.Fatal("exchange with the wrong PKCE verifier succeeded")
+	}
+	code = srv.Authorize(s256Challenge(verifier), scopes)
+	tok, err := auth.exchange(code, verifier)
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	if !tok.valid() || tok.RefreshToken == "" || !tok.coversScopes() {
+		t.Fatalf("exchanged token = %+v, want a valid token with every scope", tok)
+	}
+	if _, err := auth.exchange(code, verifier); err == nil {
+		t.Error("an authorization code was redeemed twice")
+	}
+
+	fresh, err := auth.Refresh(tok)
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if fresh.AccessToken == tok.AccessToken || !fresh.valid() {
+		t.Errorf("refresh returned %+v, want a new valid access token", fresh)
+	}
+	if fresh.RefreshToken != tok.RefreshToken {
+		t.Errorf("refresh token = %q, want the old one kept (%q)", fresh.RefreshToken, tok.RefreshToken)
+	}
+	saved, err := auth.LoadToken()
+	if err != nil || saved == nil || saved.AccessToken != fresh.AccessToken {
+		t.Errorf("saved token = %+v, %v; want the refreshed one", saved, err)
+	}
+
+	srv.RevokeRefreshTokens()
+	if _, err := auth.Refresh(fresh); err == nil {
+		t.Error("refresh with a revoked refresh token succeeded")
+	}
+}
@@ -0,0 +1,258 @@
+package webapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache is a persistent HTTP cache for Web API GET responses. Entries are kept
+// fresh for a per-endpoint TTL and revalidated with ETag/If-None-Match after
+// that, so an unchanged library costs a 304 instead of a full download. Each
+// Spotify account gets its own directory, since /me responses differ per user
+// under the same URL. Within it, entries are sharded by collection (see shard),
+// so invalidating one playlist reads only that playlist's entries.
+type Cache struct {
+	dir string
+	// refresh ignores freshness: every lookup revalidates with the server.
+	refresh bool
+	// maxBytes bounds the size of the cache; see prune.
+	maxBytes int64
+}
+
+const (
+	// staleKeep is how long an expired entry with an ETag is kept for
+	// revalidation; expired entries without one are useless and pruned.
+	staleKeep = 7 * 24 * time.Hour
+	// maxCacheBytes is the default bound on one account's cache.
+	maxCacheBytes = 64 << 20
+)
+
+// cacheEntry is the on-disk form of a cached response.
+type cacheEntry struct {
+	URL    string          `json:"url"`
+	Path   string          `json:"path"` // URL relative to the API base
+	ETag   string          `json:"etag,omitempty"`
+	Stored time.Time       `json:"stored"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// NewCache creates a cache stored under dir (created on first write). With
+// refresh set, cached entries are never served without revalidation, which is
+// what the --refresh flag selects.
+func NewCache(dir string, refresh bool) *Cache {
+	return &Cache{dir: dir, refresh: refresh, maxBytes: maxCacheBytes}
+}
+
+// forUser returns the cache of one Spotify account, in a subdirectory.
+func (c *Cache) forUser(id string) *Cache {
+	sum := sha256.Sum256([]byte(id))
+	return &Cache{dir: filepath.Join(c.dir, "user-"+hex.EncodeToString(sum[:8])), refresh: c.refresh, maxBytes: c.maxBytes}
+}
+
+// cacheTTL returns how long a response for path stays fresh without
+// revalidation. Zero means it is not cached at all (e.g. live player state).
+func cacheTTL(path string) time.Duration {
+	switch {
+	case strings.Contains(path, "/contains"):
+		return 0 // saved-state checks must reflect the latest toggle
+	case strings.HasPrefix(path, "/albums/"):
+		return 7 * 24 * time.Hour // album track lists never change
+	case strings.HasPrefix(path, "/tracks/"):
+		return 7 * 24 * time.Hour
+	case strings.HasPrefix(path, "/artists/"):
+		return 24 * time.Hour
+	case strings.HasPrefix(path, "/search"):
+		return time.Hour
+	case path == "/me" || strings.HasPrefix(path, "/me?"):
+		return time.Hour
+	case strings.HasPrefix(path, "/me/albums"),
+		strings.HasPrefix(path, "/me/playlists"),
+		strings.HasPrefix(path, "/me/shows"),
+		strings.HasPrefix(path, "/playlists/"):
+		return 10 * time.Minute
+	case strings.HasPrefix(path, "/me/tracks"):
+		return 5 * time.Minute
+	case strings.HasPrefix(path, "/me/top/"):
+		return time.Hour // affinities are recomputed about daily
+	}
+	return 0
+}
+
+// shard returns the collection a path (relative to the API base) belongs to:
+// its first two segments, such as "me/tracks" or "playlists/{id}".
+func shard(path string) string {
+	path, _, _ = strings.Cut(strings.TrimPrefix(path, "/"), "?")
+	segs := strings.SplitN(path, "/", 3)
+	return strings.Join(segs[:min(len(segs), 2)], "/")
+}
+
+// shardDir returns the directory holding the entries of path's collection.
+func (c *Cache) shardDir(path string) string {
+	sum := sha256.Sum256([]byte(shard(path)))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:8]))
+}
+
+// file returns the file holding the entry for url, whose path relative to the
+// API base is path.
+func (c *Cache) file(path, url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.shardDir(path), hex.EncodeToString(sum[:16])+".json")
+}
+
+// load returns the cached entry for url, or nil.
+func (c *Cache) load(path, url string) *cacheEntry {
+	data, err := os.ReadFile(c.file(path, url))
+	if err != nil {
+		return nil
+	}
+	var e cacheEntry
+	if json.Unmarshal(data, &e) != nil || e.URL != url {
+		return nil
+	}
+	return &e
+}
+
+// fresh reports whether e can be served without contacting the server.
+func (c *Cache) fresh(e *cacheEntry, ttl time.Duration) bool {
+	return !c.refresh && time.Since(e.Stored) < ttl
+}
+
+// store writes an entry atomically so a concurrent reader never sees a
+// partial file.
+func (c *Cache) store(e *cacheEntry) error {
+	dir := c.shardDir(e.Path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.file(e.Path, e.URL))
+}
+
+// Invalidate drops every cached entry whose path (relative to the API base)
+// starts with prefix, e.g. after the user edits a playlist. prefix names a
+// collection ("/me/tracks", "/playlists/{id}") or something within one; only
+// that collection's entries are read.
+func (c *Cache) Invalidate(prefix string) {
+	files, _ := filepath.Glob(filepath.Join(c.shardDir(prefix), "*.json"))
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var e struct {
+			Path string `json:"path"`
+		}
+		if json.Unmarshal(data, &e) == nil && strings.HasPrefix(e.Path, prefix) {
+			os.Remove(f)
+		}
+	}
+}
+
+// prune deletes expired entries that cannot be revalidated, then the entries
+// stored longest ago until the cache fits in maxBytes.
+func (c *Cache) prune() {
+	type file struct {
+		name   string
+		size   int64
+		stored time.Time
+	}
+	paths, _ := filepath.Glob(filepath.Join(c.dir, "*", "*.json"))
+	var (
+		files []file
+		total int64
+	)
+	for _, f := range paths {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var e struct {
+			Path   string    `json:"path"`
+			ETag   string    `json:"etag"`
+			Stored time.Time `json:"stored"`
+		}
+		if json.Unmarshal(data, &e) != nil {
+			os.Remove(f)
+			continue
+		}
+		age, ttl := time.Since(e.Stored), cacheTTL(e.Path)
+		if age > ttl && (e.ETag == "" || age > ttl+staleKeep) {
+			os.Remove(f)
+			continue
+		}
+		files = append(files, file{f, int64(len(data)), e.Stored})
+		total += int64(len(data))
+	}
+	if total <= c.maxBytes {
+		return
+	}
+	slices.SortFunc(files, func(a, b file) int { return a.stored.Compare(b.stored) })
+	for _, f := range files {
+		if total <= c.maxBytes {
+			break
+		}
+		if os.Remove(f.name) == nil {
+			total -= f.size
+		}
+	}
+}
+
+// flightGroup collapses concurrent identical requests into one: the first
+// caller fetches, the others wait for and share its result.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	wg   sync.WaitGroup
+	body []byte
+	err  error
+}
+
+func (g *flightGroup) do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.body, call.err
+	}
+	call := &flightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.body, call.err = fn()
+	call.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	return call.body, call.err
+}
@@ -0,0 +1,110 @@
+package webapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheIsPerUser(t *testing.T) {
+	root := NewCache(t.TempDir(), false)
+	alice, bob := root.forUser("alice"), root.forUser("bob")
+	if alice.dir == bob.dir {
+		t.Fatal("two accounts share a cache directory")
+	}
+	url := "https://api.spotify.com/v1/me/playlists"
+	if err := alice.store(&cacheEntry{URL: url, Path: "/me/playlists", Stored: time.Now(), Body: []byte(`{"owner":"alice"}`)}); err != nil {
+		t.Fatal(err)
+	}
+	if alice.load("/me/playlists", url) == nil {
+		t.Error("alice's entry is missing")
+	}
+	if bob.load("/me/playlists", url) != nil {
+		t.Error("bob sees alice's /me/playlists")
+	}
+}
+
+func TestCachePrune(t *testing.T) {
+	c := NewCache(t.TempDir(), false)
+	now := time.Now()
+	entries := map[string]*cacheEntry{
+		"fresh search":           {Path: "/search?q=a", Stored: now},
+		"expired search":         {Path: "/search?q=b", Stored: now.Add(-2 * time.Hour)},
+		"expired with etag":      {Path: "/me/playlists", ETag: `"x"`, Stored: now.Add(-time.Hour)},
+		"long expired with etag": {Path: "/me/tracks", ETag: `"y"`, Stored: now.Add(-30 * 24 * time.Hour)},
+		"uncacheable":            {Path: "/me/player", Stored: now},
+	}
+	for name, e := range entries {
+		e.URL = "https://api.spotify.com/v1" + e.Path
+		e.Body = []byte(`{"name":"` + name + `"}`)
+		if err := c.store(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.prune()
+
+	want := map[string]bool{"fresh search": true, "expired with etag": true}
+	for name, e := range entries {
+		if got := c.load(e.Path, e.URL) != nil; got != want[name] {
+			t.Errorf("%s: kept = %v, want %v", name, got, want[name])
+		}
+	}
+}
+
+func TestCachePruneBoundsSize(t *testing.T) {
+	c := NewCache(t.TempDir(), false)
+	now := time.Now()
+	var urls []string
+	for i, age := range []time.Duration{3, 2, 1} {
+		url := "https://api.spotify.com/v1/albums/" + string(rune('a'+i))
+		urls = append(urls, url)
+		e := &cacheEntry{URL: url, Path: "/albums/x", Stored: now.Add(-age * time.Minute), Body: []byte(`"x"`)}
+		if err := c.store(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	info, err := os.Stat(c.file("/albums/x", urls[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.maxBytes = 2*info.Size() + 16 // room for two entries, not three
+	c.prune()
+
+	if c.load("/albums/x", urls[0]) != nil {
+		t.Error("the oldest entry survived pruning to size")
+	}
+	for _, u := range urls[1:] {
+		if c.load("/albums/x", u) == nil {
+			t.Errorf("%s was pruned, want kept", u)
+		}
+	}
+	if files, _ := filepath.Glob(filepath.Join(c.dir, "*", "*.json")); len(files) != 2 {
+		t.Errorf("%d files left, want 2", len(files))
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	c := NewCache(t.TempDir(), false)
+	paths := []string{
+		"/playlists/pl1",
+		"/playlists/pl1/items?offset=0&limit=100",
+		"/playlists/pl10/items?offset=0&limit=100",
+		"/me/playlists?limit=50",
+		"/me",
+	}
+	for _, p := range paths {
+		if err := c.store(&cacheEntry{URL: "https://api.spotify.com/v1" + p, Path: p, Stored: time.Now(), Body: []byte(`{}`)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.Invalidate("/playlists/pl1")
+	c.Invalidate("/me/playlists")
+
+	want := map[string]bool{"/playlists/pl10/items?offset=0&limit=100": true, "/me": true}
+	for _, p := range paths {
+		if got := c.load(p, "https://api.spotify.com/v1"+p) != nil; got != want[p] {
+			t.Errorf("%s: kept = %v, want %v", p, got, want[p])
+		}
+	}
+}
@@ -1,104 +1,291 @@
 package webapi
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"sync"
+	"strings"
 	"time"
 )
 
-const apiBase = "https://api.spotify.com/v1"
-
 // Client calls the Spotify Web API with a bearer token, refreshing it
-// automatically when it expires.
+// automatically when it expires. Rate-limited and transient failures are
+// retried with backoff, and in-flight requests are capped by a shared limiter.
 type Client struct {
-	auth *Authenticator
-	http *http.Client
+	auth    *Authenticator
+	http    *http.Client
+	base    string        // Web API base URL
+	sem     chan struct{} // concurrency limiter, maxConcurrent slots
+	retries chan RetryEvent
+	cache   *Cache // nil disables the on-disk cache
+	flight  flightGroup
+
+	searchMax int // largest accepted search limit
+
+	tokens *tokenSource
+}
+
+// StatusError is an API response with an unexpected HTTP status, for callers
+// that handle some statuses (e.g. 403 for playlists they may not read).
+type StatusError struct {
+	Method string
+	Path   string
+	Status int
+	Body   string
+}
 
-	mu  sync.Mutex
-	tok *Token
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s returned %d: %s", e.Method, e.Path, e.Status, e.Body)
 }
 
 // NewClient returns a Client. It loads a saved token if present; otherwise it
-// runs the interactive login flow. Either way the token is valid on return.
+// runs the interactive login flow. Either way the token is valid on return,
+// and it is kept fresh in the background until Close.
 func NewClient(auth *Authenticator) (*Client, error) {
 	c := &Client{
-		auth: auth,
-		http: &http.Client{Timeout: 15 * time.Second},
-		tok:  auth.LoadToken(),
+		auth:    auth,
+		http:    &http.Client{Timeout: 15 * time.Second},
+		base:    auth.endpoints.API,
+		sem:     make(chan struct{}, maxConcurrent),
+		retries: make(chan RetryEvent, 16),
+
+		searchMax: DefaultSearchLimitMax,
 	}
 
-	if c.tok == nil {
-		tok, err := auth.Login()
-		if err != nil {
+	tok, err := auth.LoadToken()
+	if err != nil {
+		return nil, fmt.Errorf("loading Web API token: %w", err)
+	}
+
+	if tok != nil && !tok.coversScopes() {
+		// New features need new scopes; only a fresh consent grants them.
+		fmt.Println("[i] The Spotify Web API login needs additional permissions.")
+		tok = nil
+	}
+
+	if tok == nil {
+		if tok, err = auth.Login(); err != nil {
 			return nil, err
 		}
-		c.tok = tok
-	} else if !c.tok.valid() {
-		tok, err := auth.Refresh(c.tok)
+	}
+	c.tokens = newTokenSource(auth, tok)
+	if _, err := c.tokens.token(); err != nil {
+		// Refresh failed (revoked/expired) — fall back to a fresh login.
+		tok, err := auth.Login()
 		if err != nil {
-			// Refresh failed (revoked/expired) — fall back to a fresh login.
-			tok, err = auth.Login()
-			if err != nil {
-				return nil, err
-			}
+			c.Close()
+			return nil, err
 		}
-		c.tok = tok
+		c.tokens.set(tok)
 	}
 
 	return c, nil
 }
 
+// Close stops the background token refresh.
+func (c *Client) Close() {
+	c.tokens.close()
+}
+
+// UseCache enables the persistent response cache for GET requests. The
+// cache is keyed by the signed-in account, which is looked up first (without
+// the cache); expired entries are pruned in the background.
+func (c *Client) UseCache(cache *Cache) error {
+	u, err := c.CurrentUser()
+	if err != nil {
+		return fmt.Errorf("looking up the account for the cache: %w", err)
+	}
+	c.cache = cache.forUser(u.ID)
+	go c.cache.prune()
+	return nil
+}
+
 // accessToken returns a currently-valid access token, refreshing if needed.
 func (c *Client) accessToken() (string, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if !c.tok.valid() {
-		tok, err := c.auth.Refresh(c.tok)
-		if err != nil {
-			return "", err
-		}
-		c.tok = tok
+	tok, err := c.tokens.token()
+	if err != nil {
+		return "", err
 	}
-	return c.tok.AccessToken, nil
+	return tok.AccessToken, nil
 }
 
 // get performs an authenticated GET against the Web API and decodes the JSON
-// response into out. path is relative to apiBase; query may be nil.
+// response into out. path is relative to the API base; query may be nil.
 func (c *Client) get(path string, query url.Values, out any) error {
-	full := apiBase + path
+	full := c.base + path
 	if len(query) > 0 {
 		full += "?" + query.Encode()
 	}
+	return c.getURL(full, out)
+}
 
-	resp, err := c.do(http.MethodGet, full)
+// getURL is get for an absolute URL, such as a paging object's "next" link.
+// Identical concurrent calls share one request.
+func (c *Client) getURL(full string, out any) error {
+	body, err := c.flight.do(full, func() ([]byte, error) {
+		return c.fetch(full)
+	})
 	if err != nil {
 		return err
 	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
+
+// fetch returns the response body for a GET, served from the cache while
+// fresh and revalidated with If-None-Match once stale.
+func (c *Client) fetch(full string) ([]byte, error) {
+	path := strings.TrimPrefix(full, c.base)
+	ttl := cacheTTL(path)
+
+	var cached *cacheEntry
+	if c.cache != nil && ttl > 0 {
+		cached = c.cache.load(path, full)
+		if cached != nil && c.cache.fresh(cached, ttl) {
+			return cached.Body, nil
+		}
+	}
+
+	hdr := http.Header{}
+	if cached != nil && cached.ETag != "" {
+		hdr.Set("If-None-Match", cached.ETag)
+	}
+	resp, err := c.do(http.MethodGet, full, hdr, nil, true)
+	if err != nil {
+		return nil, err
+	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		cached.Stored = time.Now()
+		_ = c.cache.store(cached)
+		return cached.Body, nil
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if d, ok := retryAfter(resp); ok {
+			return nil, fmt.Errorf("GET %s: rate limited by Spotify, retry after %v", path, d)
+		}
+		return nil, fmt.Errorf("GET %s: rate limited by Spotify", path)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("GET %s returned %d: %s", path, resp.StatusCode, body)
+		return nil, &StatusError{Method: http.MethodGet, Path: path, Status: resp.StatusCode, Body: string(body)}
 	}
-	if out == nil {
+
+	if c.cache != nil && ttl > 0 && json.Valid(body) {
+		_ = c.cache.store(&cacheEntry{
+			URL:    full,
+			Path:   path,
+			ETag:   resp.Header.Get("ETag"),
+			Stored: time.Now(),
+			Body:   body,
+		})
+	}
+	return body, nil
+}
+
+// write issues a write request (POST, PUT, DELETE) with an optional JSON body
+// and decodes the JSON response into out, if any. path is relative to the API base.
+// Cached GET responses under any of the invalidate prefixes are dropped on
+// success, so the next read sees the change.
+func (c *Client) write(method, path string, query url.Values, in, out any, invalidate ...string) error {
+	return c.writeRetrying(idempotent(method), method, path, query, in, out, invalidate...)
+}
+
+// writeOnce is write for requests that must not be resent after a 5xx or a
+// dropped connection even though their method is idempotent, such as a
+// positional reorder: applied twice, it moves a different item.
+func (c *Client) writeOnce(method, path string, query url.Values, in, out any, invalidate ...string) error {
+	return c.writeRetrying(false, method, path, query, in, out, invalidate...)
+}
+
+func (c *Client) writeRetrying(idem bool, method, path string, query url.Values, in, out any, invalidate ...string) error {
+	full := c.base + path
+	if len(query) > 0 {
+		full += "?" + query.Encode()
+	}
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	resp, err := c.do(method, full, nil, body, idem)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("%s %s: rate limited by Spotify", method, path)
+	}
+	if resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(resp.Body)
+		return &StatusError{Method: method, Path: path, Status: resp.StatusCode, Body: string(b)}
+	}
+	if c.cache != nil {
+		for _, prefix := range invalidate {
+			c.cache.Invalidate(prefix)
+		}
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
 		return nil
 	}
 	return json.NewDecoder(resp.Body).Decode(out)
 }
 
-// do issues the request with a bearer token, refreshing once on a 401.
-func (c *Client) do(method, fullURL string) (*http.Response, error) {
+// do issues the request, retrying transient failures (429 with Retry-After,
+// 5xx, dropped connections) with jittered exponential backoff. Unless idem is
+// set, only 429s, which the server did not process, are retried.
+func (c *Client) do(method, fullURL string, hdr http.Header, body []byte, idem bool) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(method, fullURL, hdr, body)
+		wait, retry := retryDelay(idem, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+
+		ev := RetryEvent{
+			Method:  method,
+			Path:    strings.TrimPrefix(fullURL, c.base),
+			Attempt: attempt,
+			Wait:    wait,
+		}
+		if resp != nil {
+			ev.Status = resp.StatusCode
+			ev.RateLimited = resp.StatusCode == http.StatusTooManyRequests
+			drain(resp)
+		}
+		select {
+		case c.retries <- ev:
+		default:
+		}
+		time.Sleep(wait)
+	}
+}
+
+// send performs a single attempt with a bearer token, refreshing once on a
+// 401. It holds a limiter slot for the duration of the request.
+func (c *Client) send(method, fullURL string, hdr http.Header, body []byte) (*http.Response, error) {
+	c.sem <- struct{}{}
+	defer func() { <-c.sem }()
+
 	token, err := c.accessToken()
 	if err != nil {
 		return nil, err
 	}
 
-	req, _ := http.NewRequest(method, fullURL, nil)
-	req.Header.Set("Authorization", "Bearer "+token)
+	req := newRequest(method, fullURL, hdr, body, token)
 	resp, err := c.http.Do(req)
 	if err != nil {
 		return nil, err
@@ -106,23 +293,34 @@ func (c *Client) do(method, fullURL string) (*http.Response, error) {
 
 	if resp.StatusCode == http.StatusUnauthorized {
 		resp.Body.Close()
-		c.mu.Lock()
-		tok, rerr := c.auth.Refresh(c.tok)
-		if rerr == nil {
-			c.tok = tok
-		}
-		c.mu.Unlock()
-		if rerr != nil {
-			return nil, fmt.Errorf("unauthorized and token refresh failed: %w", rerr)
+		tok, err := c.tokens.rejected(token)
+		if err != nil {
+			return nil, fmt.Errorf("unauthorized and token refresh failed: %w", err)
 		}
-		req, _ := http.NewRequest(method, fullURL, nil)
-		req.Header.Set("Authorization", "Bearer "+tok.AccessToken)
-		return c.http.Do(req)
+		return c.http.Do(newRequest(method, fullURL, hdr, body, tok.AccessToken))
 	}
 
 	return resp, nil
 }
 
+// newRequest builds a request carrying hdr, an optional JSON body and the
+// bearer token. The body is a byte slice so retries can resend it.
+func newRequest(method, fullURL string, hdr http.Header, body []byte, token string) *http.Request {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, _ := http.NewRequest(method, fullURL, r)
+	for k, v := range hdr {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
 // CurrentUser fetches the authenticated user's profile (GET /me).
 func (c *Client) CurrentUser() (*User, error) {
 	var u User
@@ -0,0 +1,215 @@
+package webapi
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"cli_spotify/internal/webapi/webapitest"
+)
+
+// newTestClient returns a client logged in to srv with a saved token, so no
+// interactive login runs.
+func newTestClient(t *testing.T, srv *webapitest.Server) *Client {
+	t.Helper()
+	auth := newTestAuth(t, srv)
+	access, refresh, expiry := srv.IssueToken(time.Hour, scopes)
+	if err := auth.saveToken(&Token{AccessToken: access, RefreshToken: refresh, Expiry: expiry, Scope: scopes}); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func count(reqs []string, want string) int {
+	n := 0
+	for _, r := range reqs {
+		if r == want {
+			n++
+		}
+	}
+	return n
+}
+
+func TestUnauthorizedRefreshesOnceAndRetries(t *testing.T) {
+	srv := webapitest.New()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	srv.ExpireAccessTokens()
+	u, err := c.CurrentUser()
+	if err != nil {
+		t.Fatalf("CurrentUser after the token was revoked: %v", err)
+	}
+	if u.ID != "tester" {
+		t.Errorf("user = %q, want tester", u.ID)
+	}
+	reqs := srv.Requests()
+	want := []string{"GET /me", "POST /api/token", "GET /me"}
+	if !slices.Equal(reqs, want) {
+		t.Errorf("requests = %q, want %q", reqs, want)
+	}
+
+	// With the refresh token gone too, the request fails after one refresh
+	// attempt instead of looping.
+	srv.ExpireAccessTokens()
+	srv.RevokeRefreshTokens()
+	if _, err := c.CurrentUser(); err == nil {
+		t.Fatal("CurrentUser succeeded without any valid token")
+	}
+	if n := count(srv.Requests(), "POST /api/token"); n != 2 {
+		t.Errorf("%d token requests, want 2", n)
+	}
+}
+
+func TestPlaylistItemsItemAndLegacyTrackField(t *testing.T) {
+	for _, legacy := range []bool{false, true} {
+		srv := webapitest.New()
+		srv.LegacyPlaylistItems = legacy
+		srv.Playlists[0].Tracks = append(srv.Playlists[0].Tracks, webapitest.Track{ID: "local", Name: "Home Recording", URI: "spotify:local:Artist:Album:Home+Recording:120"})
+		c := newTestClient(t, srv)
+
+		items, err := c.PlaylistTracks("spotify:playlist:pl1")
+		srv.Close()
+		if err != nil {
+			t.Fatalf("legacy=%v: %v", legacy, err)
+		}
+		var got []string
+		for _, it := range items {
+			got = append(got, it.URI)
+		}
+		// The local file is dropped: it cannot be played through the API.
+		want := []string{"spotify:track:p1t1", "spotify:episode:ep1", "spotify:track:p1t2"}
+		if !slices.Equal(got, want) {
+			t.Errorf("legacy=%v: items = %q, want %q", legacy, got, want)
+		}
+	}
+}
+
+func TestPlaylistEdits(t *testing.T) {
+	srv := webapitest.New()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+	const pl = "spotify:playlist:pl1"
+
+	uris := func() []string {
+		t.Helper()
+		items, err := c.PlaylistTracks(pl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, it := range items {
+			out = append(out, uriID(it.URI))
+		}
+		return out
+	}
+	step := func(name string, err error, want ...string) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := uris(); !slices.Equal(got, want) {
+			t.Fatalf("after %s: items = %q, want %q", name, got, want)
+		}
+	}
+
+	_, err := c.AddPlaylistItems(pl, []string{"spotify:track:liked1", "spotify:track:p1t2"}, 1)
+	step("insert", err, "p1t1", "liked1", "p1t2", "ep1", "p1t2")
+	_, err = c.ReorderPlaylistItems(pl, 0, 3, 1, "")
+	step("reorder", err, "liked1", "p1t2", "p1t1", "ep1", "p1t2")
+	_, err = c.RemovePlaylistItems(pl, []string{"spotify:track:p1t2"}, "")
+	step("remove", err, "liked1", "p1t1", "ep1")
+	_, err = c.ReplacePlaylistItems(pl, []string{"spotify:track:a1t1"})
+	step("replace", err, "a1t1")
+}
+
+func TestStatusErrorAndRemainingPages(t *testing.T) {
+	srv := webapitest.New()
+	defer srv.Close()
+	var want []string
+	srv.Playlists[0].Tracks = nil
+	for i := range 250 {
+		id := "t" + strconv.Itoa(i)
+		srv.Playlists[0].Tracks = append(srv.Playlists[0].Tracks, webapitest.NewTrack(id, id, "Artist"))
+		want = append(want, id)
+	}
+	c := newTestClient(t, srv)
+
+	srv.Fail("/playlists/pl1/items", webapitest.Failure{Status: 403})
+	_, err := c.PlaylistTracksPager("spotify:playlist:pl1").Remaining()
+	var status *StatusError
+	if !errors.As(err, &status) || status.Status != 403 {
+		t.Fatalf("error = %v, want a *StatusError with status 403", err)
+	}
+
+	tracks, err := c.PlaylistTracksPager("spotify:playlist:pl1").Remaining()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tr := range tracks {
+		got = append(got, uriID(tr.URI))
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %d tracks out of order or missing, want %d in order", len(got), len(want))
+	}
+}
+
+func TestPlaylistExportAndImportCalls(t *testing.T) {
+	srv := webapitest.New()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	p, err := c.Playlist("spotify:playlist:pl1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "Mine" || p.Owner.ID != "tester" || p.Tracks.Total != 3 || p.SnapshotID == "" {
+		t.Errorf("Playlist() = %+v", p)
+	}
+	if _, err := c.Playlist("spotify:playlist:missing"); err == nil {
+		t.Error("Playlist() of an unknown playlist succeeded")
+	}
+
+	tracks, err := c.Tracks([]string{"spotify:track:p1t1", "spotify:track:unknown", "spotify:track:liked2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 2 || tracks[0].Name != "Road Trip" || tracks[1].Name != "Noon Song" {
+		t.Errorf("Tracks() = %+v, want Road Trip and Noon Song", tracks)
+	}
+	episodes, err := c.Episodes([]string{"spotify:episode:ep1", "spotify:episode:unknown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(episodes) != 1 || episodes[0].Name != "An Episode" {
+		t.Errorf("Episodes() = %+v, want An Episode", episodes)
+	}
+
+	created, err := c.CreatePlaylist("Imported", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uris := []string{"spotify:track:p1t1", "spotify:episode:ep1", "spotify:track:p1t1"}
+	if _, err := c.AddPlaylistItems(created.URI, uris, -1); err != nil {
+		t.Fatal(err)
+	}
+	items, err := c.PlaylistItemsPager(created.URI).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, it := range items {
+		got = append(got, it.Track.URI)
+	}
+	if !slices.Equal(got, uris) {
+		t.Errorf("imported items = %q, want %q", got, uris)
+	}
+}
@@ -0,0 +1,16 @@
+package webapi
+
+// Endpoints are the base URLs the package talks to. They default to Spotify's
+// and are only overridden to point at a fake server (see package webapitest).
+type Endpoints struct {
+	API       string // Web API base, without a trailing slash
+	Authorize string // OAuth authorize page
+	Token     string // OAuth token endpoint
+}
+
+// DefaultEndpoints are Spotify's production endpoints.
+var DefaultEndpoints = Endpoints{
+	API:       "https://api.spotify.com/v1",
+	Authorize: "https://accounts.spotify.com/authorize",
+	Token:     "https://accounts.spotify.com/api/token",
+}
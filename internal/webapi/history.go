@@ -0,0 +1,82 @@
+package webapi
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// TimeRange is the period GET /me/top/{type} computes affinity over.
+type TimeRange string
+
+const (
+	ShortTerm  TimeRange = "short_term"  // roughly the last four weeks
+	MediumTerm TimeRange = "medium_term" // roughly the last six months
+	LongTerm   TimeRange = "long_term"   // roughly the last year
+)
+
+// Label returns a short human-readable name for the range.
+func (r TimeRange) Label() string {
+	switch r {
+	case ShortTerm:
+		return "last 4 weeks"
+	case LongTerm:
+		return "last year"
+	}
+	return "last 6 months"
+}
+
+// PlayHistory is one entry of the recently played list.
+type PlayHistory struct {
+	Track    Track     `json:"track"`
+	PlayedAt time.Time `json:"played_at"`
+	Context  *struct {
+		URI  string `json:"uri"`
+		Type string `json:"type"` // "album", "artist", "playlist"
+	} `json:"context"`
+}
+
+// maxRecentlyPlayed is the most entries GET /me/player/recently-played
+// returns; Spotify keeps no longer history.
+const maxRecentlyPlayed = 50
+
+// RecentlyPlayed returns up to limit of the user's most recently played
+// tracks, newest first (GET /me/player/recently-played). Episodes are not
+// included by the API.
+func (c *Client) RecentlyPlayed(limit int) ([]PlayHistory, error) {
+	if limit <= 0 || limit > maxRecentlyPlayed {
+		limit = maxRecentlyPlayed
+	}
+	var resp struct {
+		Items []PlayHistory `json:"items"`
+	}
+	q := url.Values{"limit": {strconv.Itoa(limit)}}
+	if err := c.get("/me/player/recently-played", q, &resp); err != nil {
+		return nil, err
+	}
+	return compact(resp.Items, func(h PlayHistory) string { return h.Track.URI }), nil
+}
+
+// TopTracks returns all of the user's top tracks over r (GET /me/top/tracks).
+func (c *Client) TopTracks(r TimeRange) ([]Track, error) {
+	return c.TopTracksPager(r).All()
+}
+
+// TopTracksPager pages through the user's top tracks over r, most listened first.
+func (c *Client) TopTracksPager(r TimeRange) *Pager[Track] {
+	return newPager(c, "/me/top/tracks", url.Values{"time_range": {string(r)}}, 50, func(t Track) (Track, bool) {
+		return t, t.URI != ""
+	})
+}
+
+// TopArtists returns all of the user's top artists over r (GET /me/top/artists).
+func (c *Client) TopArtists(r TimeRange) ([]Artist, error) {
+	return c.TopArtistsPager(r).All()
+}
+
+// TopArtistsPager pages through the user's top artists over r, most listened first.
+func (c *Client) TopArtistsPager(r TimeRange) *Pager[Artist] {
+	return newPager(c, "/me/top/artists", url.Values{"time_range": {string(r)}}, 50, func(a Artist) (Artist, bool) {
+		return a, a.URI != ""
+	})
+}
@@ -1,99 +1,173 @@
 package webapi
 
-import "strings"
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
 
-// SavedAlbums returns the user's saved albums (GET /me/albums).
+// maxIDsPerRequest is the most IDs the library save/remove/contains endpoints
+// accept at once.
+const maxIDsPerRequest = 50
+
+// SavedAlbums returns all of the user's saved albums (GET /me/albums).
 func (c *Client) SavedAlbums() ([]Album, error) {
-	var resp struct {
-		Items []struct {
-			Album Album `json:"album"`
-		} `json:"items"`
-	}
-	if err := c.get("/me/albums", nil, &resp); err != nil {
-		return nil, err
-	}
-	albums := make([]Album, 0, len(resp.Items))
-	for _, item := range resp.Items {
-		if item.Album.ID != "" {
-			albums = append(albums, item.Album)
-		}
+	return c.SavedAlbumsPager().All()
+}
+
+// SavedAlbumsPager pages through the user's saved albums.
+func (c *Client) SavedAlbumsPager() *Pager[Album] {
+	type item struct {
+		Album Album `json:"album"`
 	}
-	return albums, nil
+	return newPager(c, "/me/albums", fromToken(), 50, func(it item) (Album, bool) {
+		return it.Album, it.Album.ID != ""
+	})
 }
 
-// AlbumTracks returns the tracks in an album (GET /albums/{id}/tracks).
+// AlbumTracks returns all tracks in an album (GET /albums/{id}/tracks).
 func (c *Client) AlbumTracks(albumID string) ([]Track, error) {
-	var resp struct {
-		Items []Track `json:"items"`
-	}
-	if err := c.get("/albums/"+albumID+"/tracks", nil, &resp); err != nil {
-		return nil, err
-	}
-	tracks := make([]Track, 0, len(resp.Items))
-	for _, t := range resp.Items {
-		if t.URI != "" {
-			tracks = append(tracks, t)
-		}
-	}
-	return tracks, nil
+	return c.AlbumTracksPager(albumID).All()
+}
+
+// AlbumTracksPager pages through the tracks in an album.
+func (c *Client) AlbumTracksPager(albumID string) *Pager[Track] {
+	return newPager(c, "/albums/"+albumID+"/tracks", fromToken(), 50, func(t Track) (Track, bool) {
+		return t, t.URI != ""
+	})
 }
 
-// UserPlaylists returns the authenticated user's playlists (GET /me/playlists).
+// UserPlaylists returns all of the authenticated user's playlists
+// (GET /me/playlists).
 func (c *Client) UserPlaylists() ([]Playlist, error) {
-	var resp struct {
-		Items []Playlist `json:"items"`
-	}
-	if err := c.get("/me/playlists", nil, &resp); err != nil {
-		return nil, err
-	}
-	return resp.Items, nil
+	return c.UserPlaylistsPager().All()
+}
+
+// UserPlaylistsPager pages through the authenticated user's playlists.
+func (c *Client) UserPlaylistsPager() *Pager[Playlist] {
+	return newPager(c, "/me/playlists", nil, 50, func(p Playlist) (Playlist, bool) {
+		return p, p.URI != ""
+	})
 }
 
-// PlaylistTracks returns the tracks in a playlist (GET /playlists/{id}/items).
+// PlaylistTracks returns all tracks and episodes in a playlist (GET /playlists/{id}/items).
 // playlistURI may be a full Spotify URI (spotify:playlist:ID) or a bare ID.
-// The February 2026 API migration renamed the endpoint from /tracks to /items
-// and the per-item field from "track" to "item"; both fields are still present.
 func (c *Client) PlaylistTracks(playlistURI string) ([]Track, error) {
-	id := uriID(playlistURI)
-	var resp struct {
-		Items []struct {
-			Item  *Track `json:"item"`  // primary field (Feb 2026+)
-			Track *Track `json:"track"` // legacy field (still populated)
-		} `json:"items"`
-	}
-	if err := c.get("/playlists/"+id+"/items", nil, &resp); err != nil {
-		return nil, err
+	return c.PlaylistTracksPager(playlistURI).All()
+}
+
+// PlaylistTracksPager pages through the tracks and episodes in a playlist.
+func (c *Client) PlaylistTracksPager(playlistURI string) *Pager[Track] {
+	return newPager(c, "/playlists/"+uriID(playlistURI)+"/items", fromToken(), 100, func(it playlistItem) (Track, bool) {
+		p, ok := it.item()
+		return p.Track, ok
+	})
+}
+
+// PlaylistItem is a playlist entry with when it was added.
+type PlaylistItem struct {
+	Track   Track
+	AddedAt time.Time
+}
+
+// PlaylistItemsPager pages through a playlist's tracks and episodes like
+// PlaylistTracksPager, keeping when each was added.
+func (c *Client) PlaylistItemsPager(playlistURI string) *Pager[PlaylistItem] {
+	return newPager(c, "/playlists/"+uriID(playlistURI)+"/items", fromToken(), 100, playlistItem.item)
+}
+
+// playlistItem is a playlist item object. The February 2026 API migration
+// renamed the endpoint from /tracks to /items and the per-item field from
+// "track" to "item"; both fields are still present.
+type playlistItem struct {
+	AddedAt time.Time `json:"added_at"`
+	Item    *Track    `json:"item"`  // primary field (Feb 2026+)
+	Track   *Track    `json:"track"` // legacy field (still populated)
+}
+
+func (it playlistItem) item() (PlaylistItem, bool) {
+	t := it.Item
+	if t == nil {
+		t = it.Track
 	}
-	tracks := make([]Track, 0, len(resp.Items))
-	for _, item := range resp.Items {
-		t := item.Item
-		if t == nil {
-			t = item.Track
-		}
-		if t != nil && t.URI != "" && strings.HasPrefix(t.URI, "spotify:track:") {
-			tracks = append(tracks, *t)
-		}
+	// Local files cannot be played through the API; tracks and podcast
+	// episodes can.
+	if t == nil || !(strings.HasPrefix(t.URI, "spotify:track:") || t.IsEpisode()) {
+		return PlaylistItem{}, false
 	}
-	return tracks, nil
+	return PlaylistItem{Track: *t, AddedAt: it.AddedAt}, true
 }
 
-// SavedTracks returns the user's Liked Songs (GET /me/tracks).
+// SavedTracks returns all of the user's Liked Songs (GET /me/tracks).
 func (c *Client) SavedTracks() ([]Track, error) {
-	var resp struct {
-		Items []struct {
-			Track *Track `json:"track"`
-		} `json:"items"`
-	}
-	if err := c.get("/me/tracks", nil, &resp); err != nil {
-		return nil, err
+	return c.SavedTracksPager().All()
+}
+
+// SavedTracksPager pages through the user's Liked Songs.
+func (c *Client) SavedTracksPager() *Pager[Track] {
+	type item struct {
+		Track *Track `json:"track"`
 	}
-	tracks := make([]Track, 0, len(resp.Items))
-	for _, item := range resp.Items {
-		if item.Track != nil && item.Track.URI != "" {
-			tracks = append(tracks, *item.Track)
+	return newPager(c, "/me/tracks", fromToken(), 50, func(it item) (Track, bool) {
+		if it.Track == nil || it.Track.URI == "" {
+			return Track{}, false
+		}
+		return *it.Track, true
+	})
+}
+
+// SaveTracks adds tracks to the user's Liked Songs (PUT /me/tracks).
+func (c *Client) SaveTracks(trackURIs []string) error {
+	return c.eachIDBatch(trackURIs, func(ids string) error {
+		return c.write(http.MethodPut, "/me/tracks", url.Values{"ids": {ids}}, nil, nil, "/me/tracks")
+	})
+}
+
+// RemoveSavedTracks removes tracks from the user's Liked Songs
+// (DELETE /me/tracks).
+func (c *Client) RemoveSavedTracks(trackURIs []string) error {
+	return c.eachIDBatch(trackURIs, func(ids string) error {
+		return c.write(http.MethodDelete, "/me/tracks", url.Values{"ids": {ids}}, nil, nil, "/me/tracks")
+	})
+}
+
+// SavedTracksContain reports, for each track, whether it is in the user's
+// Liked Songs (GET /me/tracks/contains). The result is parallel to trackURIs.
+func (c *Client) SavedTracksContain(trackURIs []string) ([]bool, error) {
+	out := make([]bool, 0, len(trackURIs))
+	err := c.eachIDBatch(trackURIs, func(ids string) error {
+		var resp []bool
+		if err := c.get("/me/tracks/contains", url.Values{"ids": {ids}}, &resp); err != nil {
+			return err
+		}
+		out = append(out, resp...)
+		return nil
+	})
+	return out, err
+}
+
+// eachIDBatch calls fn with comma-separated IDs for consecutive batches of
+// at most maxIDsPerRequest URIs.
+func (c *Client) eachIDBatch(uris []string, fn func(ids string) error) error {
+	for start := 0; start < len(uris); start += maxIDsPerRequest {
+		end := min(start+maxIDsPerRequest, len(uris))
+		ids := make([]string, 0, end-start)
+		for _, u := range uris[start:end] {
+			ids = append(ids, uriID(u))
+		}
+		if err := fn(strings.Join(ids, ",")); err != nil {
+			return err
 		}
 	}
-	return tracks, nil
+	return nil
+}
+
+// fromToken is the query selecting the user's own market, for endpoints that
+// take one. With a market, Spotify relinks tracks to versions playable there
+// and reports playability (Track.IsPlayable, Track.Restrictions).
+func fromToken() url.Values {
+	return url.Values{"market": {"from_token"}}
 }
 
 // uriID extracts the resource ID from a Spotify URI (spotify:type:id),
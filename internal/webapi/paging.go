@@ -0,0 +1,210 @@
+package webapi
+
+import (
+	"maps"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// page is a Spotify paging object, the envelope every list endpoint returns.
+type page[R any] struct {
+	Items  []R    `json:"items"`
+	Next   string `json:"next"`
+	Total  int    `json:"total"`
+	Offset int    `json:"offset"`
+}
+
+// Pager iterates over a paged Web API endpoint one page at a time, following
+// the "next" links the API returns. It is safe for concurrent use, though
+// pages are always fetched one after another.
+type Pager[T any] struct {
+	mu      sync.Mutex
+	fetch   func(next string) ([]T, string, int, error)
+	at      func(offset int) ([]T, error) // nil for pagers without offsets
+	limit   int
+	next    string
+	started bool
+	done    bool
+	total   int
+	read    int // items fetched so far
+}
+
+// newPager creates a Pager over path, requesting limit items per page. conv
+// turns each raw item into a result, dropping it when ok is false (e.g. a
+// removed track that the API returns as null).
+func newPager[R, T any](c *Client, path string, query url.Values, limit int, conv func(R) (T, bool)) *Pager[T] {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set("limit", strconv.Itoa(limit))
+
+	convert := func(resp *page[R]) []T {
+		out := make([]T, 0, len(resp.Items))
+		for _, r := range resp.Items {
+			if t, ok := conv(r); ok {
+				out = append(out, t)
+			}
+		}
+		return out
+	}
+	get := func(offset int) (*page[R], error) {
+		q := maps.Clone(q)
+		q.Set("offset", strconv.Itoa(offset))
+		var resp page[R]
+		return &resp, c.get(path+"?"+q.Encode(), nil, &resp)
+	}
+
+	p := &Pager[T]{limit: limit}
+	p.fetch = func(next string) ([]T, string, int, error) {
+		var resp *page[R]
+		var err error
+		if next == "" {
+			resp, err = get(0)
+		} else {
+			resp = &page[R]{}
+			err = c.getURL(next, resp)
+		}
+		if err != nil {
+			return nil, "", 0, err
+		}
+		return convert(resp), resp.Next, resp.Total, nil
+	}
+	p.at = func(offset int) ([]T, error) {
+		resp, err := get(offset)
+		if err != nil {
+			return nil, err
+		}
+		return convert(resp), nil
+	}
+	return p
+}
+
+// StaticPager returns a Pager that yields items as a single page, for lists
+// already held locally (e.g. the library mirror) shown where a Pager is
+// expected. total is the size of the list items were taken from; it exceeds
+// len(items) when some were left out.
+func StaticPager[T any](items []T, total int) *Pager[T] {
+	p := &Pager[T]{}
+	p.fetch = func(string) ([]T, string, int, error) {
+		return items, "", total, nil
+	}
+	return p
+}
+
+// Next fetches the next page. It returns nil once the last page has been read;
+// check Done to distinguish that from an empty page.
+func (p *Pager[T]) Next() ([]T, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done {
+		return nil, nil
+	}
+	next := ""
+	if p.started {
+		next = p.next
+	}
+	items, nextURL, total, err := p.fetch(next)
+	if err != nil {
+		return nil, err
+	}
+	p.started = true
+	p.next = nextURL
+	p.total = total
+	p.done = nextURL == ""
+	p.read++
+	return items, nil
+}
+
+// Remaining reads every page not read yet and returns their combined items.
+// Once the first page has given the total, the others are requested by
+// maxConcurrent workers, the client's limit on requests in flight, rather
+// than one "next" link at a time.
+func (p *Pager[T]) Remaining() ([]T, error) {
+	if !p.Started() {
+		first, err := p.Next()
+		if err != nil || p.Done() {
+			return first, err
+		}
+		rest, err := p.Remaining()
+		return append(first, rest...), err
+	}
+
+	p.mu.Lock()
+	if p.done || p.at == nil {
+		p.mu.Unlock()
+		return p.All()
+	}
+	var offsets []int
+	for off := p.read * p.limit; off < p.total; off += p.limit {
+		offsets = append(offsets, off)
+	}
+	p.mu.Unlock()
+
+	pages := make([][]T, len(offsets))
+	errs := make([]error, len(offsets))
+	next := make(chan int, len(offsets))
+	for i := range offsets {
+		next <- i
+	}
+	close(next)
+	var wg sync.WaitGroup
+	for range min(maxConcurrent, len(offsets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				pages[i], errs[i] = p.at(offsets[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	var all []T
+	for i := range pages {
+		if errs[i] != nil {
+			return all, errs[i]
+		}
+		all = append(all, pages[i]...)
+	}
+	p.mu.Lock()
+	p.done = true
+	p.read += len(offsets)
+	p.mu.Unlock()
+	return all, nil
+}
+
+// Started reports whether the first page has been fetched.
+func (p *Pager[T]) Started() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.started
+}
+
+// All reads every remaining page and returns the combined items.
+func (p *Pager[T]) All() ([]T, error) {
+	var all []T
+	for !p.Done() {
+		items, err := p.Next()
+		if err != nil {
+			return all, err
+		}
+		all = append(all, items...)
+	}
+	return all, nil
+}
+
+// Done reports whether the last page has been fetched.
+func (p *Pager[T]) Done() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.done
+}
+
+// Total returns the item count the API reported, or 0 before the first page.
+func (p *Pager[T]) Total() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.total
+}
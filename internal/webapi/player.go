@@ -0,0 +1,58 @@
+package webapi
+
+import "net/http"
+
+// Device is a Spotify Connect device visible to the user's account.
+type Device struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	Type             string `json:"type"` // "Computer", "Smartphone", "Speaker", ...
+	IsActive         bool   `json:"is_active"`
+	IsPrivateSession bool   `json:"is_private_session"`
+	IsRestricted     bool   `json:"is_restricted"` // accepts no Web API commands
+	VolumePercent    *int   `json:"volume_percent"`
+	SupportsVolume   bool   `json:"supports_volume"`
+}
+
+// Devices lists the user's available Connect devices
+// (GET /me/player/devices).
+func (c *Client) Devices() ([]Device, error) {
+	var resp struct {
+		Devices []Device `json:"devices"`
+	}
+	if err := c.get("/me/player/devices", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Devices, nil
+}
+
+// TransferPlayback moves playback to the device (PUT /me/player). With play
+// set, playback starts there even if it was paused; otherwise the current
+// state is kept.
+func (c *Client) TransferPlayback(deviceID string, play bool) error {
+	body := map[string]any{
+		"device_ids": []string{deviceID},
+		"play":       play,
+	}
+	return c.write(http.MethodPut, "/me/player", nil, body, nil)
+}
+
+// Queue is the user's playback queue as Spotify sees it.
+type Queue struct {
+	// CurrentlyPlaying is nil when nothing is playing. Episodes decode into
+	// a Track without artists or album.
+	CurrentlyPlaying *Track `json:"currently_playing"`
+	// Queue lists what plays next: the user's queued items first, then the
+	// upcoming tracks of the playing context.
+	Queue []Track `json:"queue"`
+}
+
+// Queue returns the current playback queue (GET /me/player/queue).
+func (c *Client) Queue() (*Queue, error) {
+	var q Queue
+	if err := c.get("/me/player/queue", nil, &q); err != nil {
+		return nil, err
+	}
+	q.Queue = compact(q.Queue, func(t Track) string { return t.URI })
+	return &q, nil
+}
@@ -0,0 +1,145 @@
+package webapi
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// maxPlaylistBatch is the most items a single playlist add/remove accepts.
+const maxPlaylistBatch = 100
+
+// PlaylistDetails are the editable attributes of a playlist. Nil fields are
+// left unchanged.
+type PlaylistDetails struct {
+	Name          *string `json:"name,omitempty"`
+	Description   *string `json:"description,omitempty"`
+	Public        *bool   `json:"public,omitempty"`
+	Collaborative *bool   `json:"collaborative,omitempty"`
+}
+
+// snapshot is the response of every playlist item mutation.
+type snapshot struct {
+	SnapshotID string `json:"snapshot_id"`
+}
+
+// Playlist fetches a playlist's details (GET /playlists/{id}), without its
+// items. playlistURI may be a Spotify URI or a bare ID.
+func (c *Client) Playlist(playlistURI string) (*Playlist, error) {
+	var p Playlist
+	q := url.Values{"fields": {"id,uri,name,description,public,collaborative,snapshot_id,owner,tracks.total"}}
+	if err := c.get("/playlists/"+uriID(playlistURI), q, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// CreatePlaylist creates a playlist owned by the current user
+// (POST /me/playlists).
+func (c *Client) CreatePlaylist(name, description string, public bool) (*Playlist, error) {
+	body := map[string]any{
+		"name":        name,
+		"description": description,
+		"public":      public,
+	}
+	var p Playlist
+	if err := c.write(http.MethodPost, "/me/playlists", nil, body, &p, "/me/playlists"); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ChangePlaylistDetails renames or otherwise edits a playlist
+// (PUT /playlists/{id}).
+func (c *Client) ChangePlaylistDetails(playlistURI string, d PlaylistDetails) error {
+	id := uriID(playlistURI)
+	return c.write(http.MethodPut, "/playlists/"+id, nil, d, nil, "/playlists/"+id, "/me/playlists")
+}
+
+// AddPlaylistItems inserts items (track or episode URIs) into a playlist
+// (POST /playlists/{id}/items). position is the zero-based insertion index, or
+// -1 to append. Large lists are sent in batches; the returned snapshot ID is
+// the playlist version after the last batch.
+func (c *Client) AddPlaylistItems(playlistURI string, uris []string, position int) (string, error) {
+	id := uriID(playlistURI)
+	var snap string
+	for start := 0; start < len(uris); start += maxPlaylistBatch {
+		end := min(start+maxPlaylistBatch, len(uris))
+		body := map[string]any{"uris": uris[start:end]}
+		if position >= 0 {
+			body["position"] = position + start
+		}
+		var resp snapshot
+		if err := c.write(http.MethodPost, "/playlists/"+id+"/items", nil, body, &resp, "/playlists/"+id, "/me/playlists"); err != nil {
+			return snap, err
+		}
+		snap = resp.SnapshotID
+	}
+	return snap, nil
+}
+
+// ReplacePlaylistItems sets a playlist's items to uris, in order
+// (PUT /playlists/{id}/items). The first batch replaces the items and the
+// rest are appended. Returns the new snapshot ID.
+func (c *Client) ReplacePlaylistItems(playlistURI string, uris []string) (string, error) {
+	id := uriID(playlistURI)
+	first := uris[:min(maxPlaylistBatch, len(uris))]
+	if first == nil {
+		first = []string{} // clears the playlist; null would be rejected
+	}
+	var resp snapshot
+	if err := c.write(http.MethodPut, "/playlists/"+id+"/items", nil, map[string]any{"uris": first}, &resp, "/playlists/"+id, "/me/playlists"); err != nil {
+		return "", err
+	}
+	if len(uris) == len(first) {
+		return resp.SnapshotID, nil
+	}
+	return c.AddPlaylistItems(playlistURI, uris[len(first):], -1)
+}
+
+// RemovePlaylistItems removes every occurrence of the given URIs from a
+// playlist (DELETE /playlists/{id}/items). snapshotID, when set, makes the
+// removal apply to that playlist version so concurrent edits are not
+// clobbered. Returns the new snapshot ID.
+func (c *Client) RemovePlaylistItems(playlistURI string, uris []string, snapshotID string) (string, error) {
+	id := uriID(playlistURI)
+	snap := snapshotID
+	for start := 0; start < len(uris); start += maxPlaylistBatch {
+		end := min(start+maxPlaylistBatch, len(uris))
+		items := make([]map[string]string, 0, end-start)
+		for _, u := range uris[start:end] {
+			items = append(items, map[string]string{"uri": u})
+		}
+		body := map[string]any{"items": items}
+		if snap != "" {
+			body["snapshot_id"] = snap
+		}
+		var resp snapshot
+		if err := c.write(http.MethodDelete, "/playlists/"+id+"/items", nil, body, &resp, "/playlists/"+id, "/me/playlists"); err != nil {
+			return snap, err
+		}
+		snap = resp.SnapshotID
+	}
+	return snap, nil
+}
+
+// ReorderPlaylistItems moves rangeLength items starting at rangeStart so they
+// sit before the item at insertBefore, all indexes being zero-based positions
+// in the playlist version snapshotID (PUT /playlists/{id}/items). Returns the
+// new snapshot ID. Server errors are not retried: the move may have been
+// applied, and applying it again would move another item.
+func (c *Client) ReorderPlaylistItems(playlistURI string, rangeStart, insertBefore, rangeLength int, snapshotID string) (string, error) {
+	id := uriID(playlistURI)
+	body := map[string]any{
+		"range_start":   rangeStart,
+		"insert_before": insertBefore,
+		"range_length":  rangeLength,
+	}
+	if snapshotID != "" {
+		body["snapshot_id"] = snapshotID
+	}
+	var resp snapshot
+	if err := c.writeOnce(http.MethodPut, "/playlists/"+id+"/items", nil, body, &resp, "/playlists/"+id); err != nil {
+		return "", err
+	}
+	return resp.SnapshotID, nil
+}
@@ -0,0 +1,131 @@
+package webapi
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxAttempts bounds how many times a request is sent in total.
+	maxAttempts = 5
+	// maxConcurrent caps in-flight Web API requests per Client; Spotify rate
+	// limits per app over a rolling window, so bursts only earn 429s.
+	maxConcurrent = 4
+	// baseBackoff and maxBackoff bound the exponential backoff between retries
+	// of transient failures that come without a Retry-After header.
+	baseBackoff = 500 * time.Millisecond
+	maxBackoff  = 30 * time.Second
+	// maxRetryAfter is the longest Retry-After we are willing to sleep through;
+	// anything longer (quota exhaustion) is returned to the caller instead.
+	maxRetryAfter = 2 * time.Minute
+)
+
+// RetryEvent reports that a request failed transiently and will be retried
+// after Wait, so the UI can show e.g. "rate limited, retrying in 5s".
+type RetryEvent struct {
+	Method      string
+	Path        string
+	Status      int // HTTP status, or 0 for a network error
+	RateLimited bool
+	Attempt     int // the attempt that failed, starting at 1
+	Wait        time.Duration
+}
+
+// Retries returns a channel of retry notifications. Events are dropped rather
+// than delaying requests when nobody is reading.
+func (c *Client) Retries() <-chan RetryEvent {
+	return c.retries
+}
+
+// retryDelay decides whether a failed attempt should be retried and how long
+// to wait first. resp is nil when err is a transport error. idem tells whether
+// the request may safely be applied twice.
+func retryDelay(idem bool, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= maxAttempts {
+		return 0, false
+	}
+	if err != nil {
+		// Network errors are retried for idempotent requests only: a POST may
+		// have reached the server before the connection dropped.
+		return backoff(attempt), idem && transientNetErr(err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		// A 429 means the request was not processed, so it is always safe to
+		// resend.
+		if d, ok := retryAfter(resp); ok {
+			return d, d <= maxRetryAfter
+		}
+		return backoff(attempt), true
+	case resp.StatusCode == http.StatusInternalServerError,
+		resp.StatusCode == http.StatusBadGateway,
+		resp.StatusCode == http.StatusServiceUnavailable,
+		resp.StatusCode == http.StatusGatewayTimeout:
+		if !idem {
+			return 0, false
+		}
+		if d, ok := retryAfter(resp); ok && d <= maxRetryAfter {
+			return d, true
+		}
+		return backoff(attempt), true
+	}
+	return 0, false
+}
+
+// retryAfter parses the Retry-After header, which Spotify sends in seconds.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// backoff returns a "full jitter" exponential delay for the given attempt:
+// uniformly random in [0, min(maxBackoff, baseBackoff*2^(attempt-1))].
+func backoff(attempt int) time.Duration {
+	d := baseBackoff << (attempt - 1)
+	if d <= 0 || d > maxBackoff {
+		d = maxBackoff
+	}
+	return rand.N(d + 1)
+}
+
+// idempotent reports whether requests with method may be resent by default.
+// Individual requests can opt out (see Client.writeOnce).
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// transientNetErr reports whether a transport error is worth retrying.
+func transientNetErr(err error) bool {
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		strings.Contains(err.Error(), "connection reset") ||
+		strings.Contains(err.Error(), "connection refused")
+}
+
+// drain discards and closes a response body so the connection can be reused.
+func drain(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+}
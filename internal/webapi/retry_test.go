@@ -0,0 +1,137 @@
+package webapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"cli_spotify/internal/webapi/webapitest"
+)
+
+func response(status int, retryAfter string) *http.Response {
+	r := &http.Response{StatusCode: status, Header: http.Header{}}
+	if retryAfter != "" {
+		r.Header.Set("Retry-After", retryAfter)
+	}
+	return r
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		idem    bool
+		resp    *http.Response
+		err     error
+		attempt int
+		retry   bool
+		wait    time.Duration // exact wait; 0 accepts any backoff
+	}{
+		{name: "429 with Retry-After", resp: response(429, "3"), attempt: 1, retry: true, wait: 3 * time.Second},
+		{name: "429 past the longest wait", resp: response(429, "600"), attempt: 1},
+		{name: "429 without Retry-After", resp: response(429, ""), attempt: 1, retry: true},
+		{name: "503 idempotent", idem: true, resp: response(503, ""), attempt: 1, retry: true},
+		{name: "503 with Retry-After", idem: true, resp: response(503, "2"), attempt: 2, retry: true, wait: 2 * time.Second},
+		{name: "503 not idempotent", resp: response(503, ""), attempt: 1},
+		{name: "404", idem: true, resp: response(404, ""), attempt: 1},
+		{name: "dropped connection idempotent", idem: true, err: io.ErrUnexpectedEOF, attempt: 1, retry: true},
+		{name: "dropped connection not idempotent", err: io.ErrUnexpectedEOF, attempt: 1},
+		{name: "other transport error", idem: true, err: errors.New("x509: certificate signed by unknown authority"), attempt: 1},
+		{name: "out of attempts", idem: true, resp: response(503, ""), attempt: maxAttempts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, retry := retryDelay(tt.idem, tt.resp, tt.err, tt.attempt)
+			if retry != tt.retry {
+				t.Fatalf("retry = %v, want %v", retry, tt.retry)
+			}
+			if retry && tt.wait != 0 && wait != tt.wait {
+				t.Errorf("wait = %v, want %v", wait, tt.wait)
+			}
+			if retry && (wait < 0 || wait > maxBackoff) {
+				t.Errorf("wait = %v, outside [0, %v]", wait, maxBackoff)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"5", 5 * time.Second, true},
+		{" 7 ", 7 * time.Second, true},
+		{"0", 0, true},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(response(429, tt.header))
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got, ok := retryAfter(response(429, future)); !ok || got <= 50*time.Second || got > time.Minute {
+		t.Errorf("retryAfter(a date a minute ahead) = %v, %v", got, ok)
+	}
+}
+
+func TestRateLimitedRequestIsRetried(t *testing.T) {
+	srv := webapitest.New()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	srv.Fail("/me", webapitest.Failure{Status: 429, RetryAfter: time.Second})
+	start := time.Now()
+	if _, err := c.CurrentUser(); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited < time.Second {
+		t.Errorf("retried after %v, before the 1s Retry-After", waited)
+	}
+	select {
+	case ev := <-c.Retries():
+		if !ev.RateLimited || ev.Status != 429 || ev.Wait != time.Second || ev.Path != "/me" {
+			t.Errorf("retry event = %+v", ev)
+		}
+	default:
+		t.Error("no retry event")
+	}
+	if n := count(srv.Requests(), "GET /me"); n != 2 {
+		t.Errorf("%d requests, want 2", n)
+	}
+}
+
+func TestReorderIsNotRetried(t *testing.T) {
+	srv := webapitest.New()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	srv.Fail("/playlists/pl1/items", webapitest.Failure{Status: 503})
+	if _, err := c.ReorderPlaylistItems("spotify:playlist:pl1", 0, 2, 1, ""); err == nil {
+		t.Fatal("the reorder succeeded despite the 503")
+	}
+	if n := count(srv.Requests(), "PUT /playlists/pl1/items"); n != 1 {
+		t.Errorf("reorder sent %d times, want once", n)
+	}
+	var ids []string
+	for _, tr := range srv.Playlists[0].Tracks {
+		ids = append(ids, tr.ID)
+	}
+	if want := []string{"p1t1", "ep1", "p1t2"}; !slices.Equal(ids, want) {
+		t.Errorf("playlist = %q, want it unchanged", ids)
+	}
+
+	// A replace is idempotent and is retried.
+	srv.Fail("/playlists/pl1/items", webapitest.Failure{Status: 503, RetryAfter: time.Second})
+	if _, err := c.ReplacePlaylistItems("spotify:playlist:pl1", []string{"spotify:track:p1t1"}); err != nil {
+		t.Fatalf("replace after one 503: %v", err)
+	}
+}
@@ -1,21 +1,127 @@
 package webapi
 
-import "net/url"
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
 
-// SearchTracks searches for tracks matching query via GET /search?type=track.
-// The number of results is capped by the app's Spotify quota (5 in dev mode).
-func (c *Client) SearchTracks(query string, _ int) ([]Track, error) {
-	q := url.Values{
-		"q":    {query},
-		"type": {"track"},
+// SearchType is a kind of item GET /search can return.
+type SearchType string
+
+const (
+	SearchTrack    SearchType = "track"
+	SearchAlbum    SearchType = "album"
+	SearchArtist   SearchType = "artist"
+	SearchPlaylist SearchType = "playlist"
+	SearchShow     SearchType = "show"
+	SearchEpisode  SearchType = "episode"
+)
+
+// AllSearchTypes lists every search type, in the order the UI shows them.
+var AllSearchTypes = []SearchType{SearchTrack, SearchAlbum, SearchArtist, SearchPlaylist, SearchShow, SearchEpisode}
+
+// DefaultSearchLimitMax is the largest per-type result count GET /search
+// accepts from an app in development mode, which is what most users register;
+// a larger limit is rejected with 400. Apps with extended quota can raise it
+// to MaxSearchLimit with SetSearchLimitMax.
+const DefaultSearchLimitMax = 10
+
+// MaxSearchLimit is the largest per-type result count GET /search accepts
+// under any quota.
+const MaxSearchLimit = 50
+
+// Results is one typed section of a search response.
+type Results[T any] struct {
+	Items  []T    `json:"items"`
+	Total  int    `json:"total"`
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+	Next   string `json:"next"` // empty on the last page
+}
+
+// More reports whether further results exist past this page.
+func (r Results[T]) More() bool {
+	return r.Next != "" && r.NextOffset() < r.Total
+}
+
+// NextOffset returns the offset of the page after this one. It is based on the
+// page size rather than len(Items), which excludes unresolvable entries.
+func (r Results[T]) NextOffset() int {
+	return r.Offset + max(r.Limit, len(r.Items))
+}
+
+// Append adds a following page to r, keeping the newest paging fields.
+func (r *Results[T]) Append(next Results[T]) {
+	r.Items = append(r.Items, next.Items...)
+	r.Total, r.Offset, r.Limit, r.Next = next.Total, next.Offset, next.Limit, next.Next
+}
+
+// SearchResults holds the typed results of a multi-type search. Sections for
+// types that were not requested are empty.
+type SearchResults struct {
+	Tracks    Results[Track]    `json:"tracks"`
+	Albums    Results[Album]    `json:"albums"`
+	Artists   Results[Artist]   `json:"artists"`
+	Playlists Results[Playlist] `json:"playlists"`
+	Shows     Results[Show]     `json:"shows"`
+	Episodes  Results[Episode]  `json:"episodes"`
+}
+
+// SetSearchLimitMax sets the quota-imposed cap on search result counts,
+// within [1, MaxSearchLimit]. Requested limits above it are clamped rather
+// than rejected by the API.
+func (c *Client) SetSearchLimitMax(n int) {
+	c.searchMax = max(1, min(n, MaxSearchLimit))
+}
+
+// Search runs GET /search for the given types, returning up to limit items of
+// each type starting at offset. limit is clamped to [1, the search limit max].
+func (c *Client) Search(query string, types []SearchType, limit, offset int) (*SearchResults, error) {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = string(t)
 	}
-	var resp struct {
-		Tracks struct {
-			Items []Track `json:"items"`
-		} `json:"tracks"`
+	limit = max(1, min(limit, c.searchMax))
+	q := url.Values{
+		"q":      {query},
+		"type":   {strings.Join(names, ",")},
+		"limit":  {strconv.Itoa(limit)},
+		"offset": {strconv.Itoa(max(0, offset))},
+		"market": {"from_token"},
 	}
+	var resp SearchResults
 	if err := c.get("/search", q, &resp); err != nil {
 		return nil, err
 	}
-	return resp.Tracks.Items, nil
+	// The API returns null for items it can no longer resolve (notably
+	// playlists); they decode as zero values.
+	resp.Tracks.Items = compact(resp.Tracks.Items, func(t Track) string { return t.URI })
+	resp.Albums.Items = compact(resp.Albums.Items, func(a Album) string { return a.URI })
+	resp.Artists.Items = compact(resp.Artists.Items, func(a Artist) string { return a.URI })
+	resp.Playlists.Items = compact(resp.Playlists.Items, func(p Playlist) string { return p.URI })
+	resp.Shows.Items = compact(resp.Shows.Items, func(s Show) string { return s.URI })
+	resp.Episodes.Items = compact(resp.Episodes.Items, func(e Episode) string { return e.URI })
+	return &resp, nil
+}
+
+// SearchTracks searches for tracks matching query via GET /search?type=track,
+// returning up to limit results (clamped to the app's quota).
+func (c *Client) SearchTracks(query string, limit int) ([]Track, error) {
+	res, err := c.Search(query, []SearchType{SearchTrack}, limit, 0)
+	if err != nil {
+		return nil, err
+	}
+	return res.Tracks.Items, nil
+}
+
+// compact drops items whose URI is empty.
+func compact[T any](items []T, uri func(T) string) []T {
+	out := items[:0]
+	for _, it := range items {
+		if uri(it) != "" {
+			out = append(out, it)
+		}
+	}
+	return out
 }
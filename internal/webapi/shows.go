@@ -0,0 +1,69 @@
+package webapi
+
+// SavedShows returns all of the user's saved podcasts (GET /me/shows).
+func (c *Client) SavedShows() ([]Show, error) {
+	return c.SavedShowsPager().All()
+}
+
+// SavedShowsPager pages through the user's saved podcasts.
+func (c *Client) SavedShowsPager() *Pager[Show] {
+	type item struct {
+		Show *Show `json:"show"`
+	}
+	return newPager(c, "/me/shows", nil, 50, func(it item) (Show, bool) {
+		if it.Show == nil || it.Show.URI == "" {
+			return Show{}, false
+		}
+		return *it.Show, true
+	})
+}
+
+// ShowEpisodes returns all episodes of a show, newest first
+// (GET /shows/{id}/episodes).
+func (c *Client) ShowEpisodes(showURI string) ([]Episode, error) {
+	return c.ShowEpisodesPager(showURI).All()
+}
+
+// ShowEpisodesPager pages through a show's episodes, newest first. Each
+// carries the user's resume point. Unavailable episodes come back as null
+// and are skipped.
+func (c *Client) ShowEpisodesPager(showURI string) *Pager[Episode] {
+	return newPager(c, "/shows/"+uriID(showURI)+"/episodes", fromToken(), 50, func(e *Episode) (Episode, bool) {
+		if e == nil || e.URI == "" {
+			return Episode{}, false
+		}
+		return *e, true
+	})
+}
+
+// Episodes looks up episodes in batches (GET /episodes?ids=...), like Tracks.
+// Episodes Spotify does not know are left out.
+func (c *Client) Episodes(uris []string) ([]Episode, error) {
+	var out []Episode
+	err := c.eachIDBatch(uris, func(ids string) error {
+		q := fromToken()
+		q.Set("ids", ids)
+		var resp struct {
+			Episodes []*Episode `json:"episodes"`
+		}
+		if err := c.get("/episodes", q, &resp); err != nil {
+			return err
+		}
+		for _, e := range resp.Episodes {
+			if e != nil && e.URI != "" {
+				out = append(out, *e)
+			}
+		}
+		return nil
+	})
+	return out, err
+}
+
+// Episode fetches a single episode with its show (GET /episodes/{id}).
+func (c *Client) Episode(uri string) (*Episode, error) {
+	var e Episode
+	if err := c.get("/episodes/"+uriID(uri), fromToken(), &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
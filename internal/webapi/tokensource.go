@@ -0,0 +1,265 @@
+package webapi
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// refreshAhead is how long before expiry the background refresh runs, so
+	// requests never wait for a refresh in the common case.
+	refreshAhead = 5 * time.Minute
+	// minRefreshInterval bounds how often the background refresh can run,
+	// e.g. for tokens shorter-lived than refreshAhead; refreshRetry is the
+	// pause after a failed background refresh.
+	minRefreshInterval = 10 * time.Second
+	refreshRetry       = time.Minute
+
+	// lockWait is how long a refresh waits for another process holding the
+	// token lock, and lockStale the age after which a lock is presumed left
+	// behind by a crashed process.
+	lockWait  = 15 * time.Second
+	lockStale = 30 * time.Second
+)
+
+// tokenSource hands out access tokens and owns refreshing them. Concurrent
+// refreshes collapse into one, and across processes (the TUI and CLI
+// subcommands share one token file) they are serialized by a lock file, with
+// each process adopting a token another one has just refreshed instead of
+// spending the refresh token again.
+type tokenSource struct {
+	auth *Authenticator
+
+	mu       sync.Mutex
+	tok      *Token
+	inflight *refreshCall
+
+	wake chan struct{} // the token changed; reschedule the background refresh
+	stop chan struct{}
+}
+
+// refreshCall is a refresh in progress that other callers wait on.
+type refreshCall struct {
+	done chan struct{}
+	tok  *Token
+	err  error
+}
+
+// newTokenSource starts a source holding tok, refreshing it in the background.
+func newTokenSource(auth *Authenticator, tok *Token) *tokenSource {
+	s := &tokenSource{
+		auth: auth,
+		tok:  tok,
+		wake: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+// token returns a valid token, refreshing first only if the background
+// refresh has not kept it fresh.
+func (s *tokenSource) token() (*Token, error) {
+	s.mu.Lock()
+	tok := s.tok
+	s.mu.Unlock()
+	if tok.valid() {
+		return tok, nil
+	}
+	return s.refresh(tok)
+}
+
+// rejected returns a replacement for an access token the API refused. If
+// another caller has already replaced it, that token is returned as is.
+func (s *tokenSource) rejected(access string) (*Token, error) {
+	s.mu.Lock()
+	tok := s.tok
+	s.mu.Unlock()
+	if tok.AccessToken != access && tok.valid() {
+		return tok, nil
+	}
+	return s.refresh(tok)
+}
+
+// set replaces the token, e.g. after a fresh interactive login.
+func (s *tokenSource) set(tok *Token) {
+	s.mu.Lock()
+	s.tok = tok
+	s.mu.Unlock()
+	s.notify()
+}
+
+// close stops the background refresh.
+func (s *tokenSource) close() {
+	close(s.stop)
+}
+
+// refresh replaces seen, the token the caller found unusable. Callers that
+// arrive while a refresh is running wait for it and share its result.
+func (s *tokenSource) refresh(seen *Token) (*Token, error) {
+	s.mu.Lock()
+	if s.tok != seen && s.tok.valid() {
+		tok := s.tok
+		s.mu.Unlock()
+		return tok, nil
+	}
+	if c := s.inflight; c != nil {
+		s.mu.Unlock()
+		<-c.done
+		return c.tok, c.err
+	}
+	c := &refreshCall{done: make(chan struct{})}
+	s.inflight = c
+	s.mu.Unlock()
+
+	c.tok, c.err = s.refreshLocked(seen)
+
+	s.mu.Lock()
+	if c.err == nil {
+		s.tok = c.tok
+	}
+	s.inflight = nil
+	s.mu.Unlock()
+	close(c.done)
+	if c.err == nil {
+		s.notify()
+	}
+	return c.tok, c.err
+}
+
+// refreshLocked refreshes under the cross-process lock. The stored token is
+// re-read first: if another process refreshed while this one waited, its
+// newer token is adopted. Otherwise the stored refresh token is used, since
+// Spotify may have rotated the one seen carries.
+func (s *tokenSource) refreshLocked(seen *Token) (*Token, error) {
+	unlock, err := lockStore(s.auth.store)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	base := seen
+	if stored, err := s.auth.LoadToken(); err == nil && stored != nil {
+		if stored.AccessToken != seen.AccessToken && stored.valid() && stored.Expiry.After(seen.Expiry) {
+			return stored, nil
+		}
+		if stored.RefreshToken != "" {
+			base = stored
+		}
+	}
+	return s.auth.Refresh(base)
+}
+
+// loop refreshes the token refreshAhead before it expires.
+func (s *tokenSource) loop() {
+	for {
+		s.mu.Lock()
+		tok := s.tok
+		s.mu.Unlock()
+
+		wait := max(time.Until(tok.Expiry.Add(-refreshAhead)), minRefreshInterval)
+		timer := time.NewTimer(wait)
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+			continue
+		case <-timer.C:
+		}
+		// Until the token actually expires, requests keep using it, so a
+		// failure only needs retrying later.
+		if _, err := s.refresh(tok); err != nil {
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(refreshRetry):
+			}
+		}
+	}
+}
+
+func (s *tokenSource) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// storeLocker is implemented by token stores shared between processes.
+type storeLocker interface {
+	lock() (unlock func(), err error)
+}
+
+// lockStore takes the store's cross-process lock, if it has one.
+func lockStore(store TokenStore) (func(), error) {
+	if l, ok := store.(storeLocker); ok {
+		return l.lock()
+	}
+	return func() {}, nil
+}
+
+func (s *FileStore) lock() (func(), error)          { return lockFile(s.path + ".lock") }
+func (s *EncryptedFileStore) lock() (func(), error) { return lockFile(s.path + ".lock") }
+
+// lockFile takes an exclusive lock by creating path, waiting up to lockWait
+// for another holder and breaking locks older than lockStale. The file holds
+// the owner's PID, for troubleshooting, and a token unique to this
+// acquisition: releasing and breaking only remove the lock they inspected,
+// never one another process has taken since.
+func lockFile(path string) (func(), error) {
+	token, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+	owner := strconv.Itoa(os.Getpid()) + " " + token
+	aside := path + "." + token
+
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, err = f.WriteString(owner)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("writing token lock: %w", err)
+			}
+			return func() { removeLockIf(path, aside, owner) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		holder, rerr := os.ReadFile(path)
+		st, serr := os.Stat(path)
+		if rerr == nil && serr == nil && time.Since(st.ModTime()) > lockStale {
+			removeLockIf(path, aside, string(holder))
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("token lock %s is held by another process", path)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// removeLockIf removes the lock at path if it still holds owner. The lock is
+// first renamed to aside, which only one process can do, and then checked; a
+// lock that turns out to be someone else's is put back (unless yet another
+// has been created meanwhile).
+func removeLockIf(path, aside, owner string) {
+	if err := os.Rename(path, aside); err != nil {
+		return
+	}
+	if b, err := os.ReadFile(aside); err != nil || string(b) != owner {
+		os.Link(aside, path)
+	}
+	os.Remove(aside)
+}
@@ -0,0 +1,44 @@
+package webapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockFileBreaksStaleLockWithoutLosingTheNewOne(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.json.lock")
+
+	unlockOld, err := lockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The holder hangs past lockStale, so the next process breaks the lock.
+	old := time.Now().Add(-2 * lockStale)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	unlockNew, err := lockFile(path)
+	if err != nil {
+		t.Fatalf("taking a stale lock: %v", err)
+	}
+	held, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The old holder finishing must not release the new holder's lock.
+	unlockOld()
+	if now, err := os.ReadFile(path); err != nil || string(now) != string(held) {
+		t.Fatalf("after the old holder released: lock = %q, %v; want %q", now, err, held)
+	}
+
+	unlockNew()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock still present after release: %v", err)
+	}
+	if left, _ := filepath.Glob(path + ".*"); len(left) != 0 {
+		t.Errorf("leftover files: %q", left)
+	}
+}
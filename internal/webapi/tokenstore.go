@@ -0,0 +1,331 @@
+package webapi
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// TokenStore persists the Web API token between runs.
+type TokenStore interface {
+	// Load returns the saved token, or nil and no error if there is none.
+	Load() (*Token, error)
+	// Save replaces the saved token.
+	Save(*Token) error
+}
+
+// FileStore keeps the token as plain JSON in a file readable only by the user.
+type FileStore struct {
+	path string
+}
+
+// NewFileStore returns a FileStore at path (e.g.
+// ~/.spotify-cli/webapi-token.json).
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load implements TokenStore.
+func (s *FileStore) Load() (*Token, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeToken(data)
+}
+
+// Save implements TokenStore.
+func (s *FileStore) Save(t *Token) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data)
+}
+
+// Remove deletes the file, e.g. once its token has moved to another store.
+func (s *FileStore) Remove() error {
+	err := os.Remove(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// MigrateToken moves a token from the plain-text file into to, unless to
+// already holds one, and deletes the plain-text copy.
+func MigrateToken(plain *FileStore, to TokenStore) error {
+	tok, err := plain.Load()
+	if err != nil || tok == nil {
+		return err
+	}
+	if existing, err := to.Load(); err != nil {
+		return err
+	} else if existing == nil {
+		if err := to.Save(tok); err != nil {
+			return err
+		}
+	}
+	return plain.Remove()
+}
+
+// pbkdf2Iterations is the PBKDF2-SHA256 work factor for passphrase keys.
+const pbkdf2Iterations = 600_000
+
+// EncryptedFileStore keeps the token in a file encrypted with AES-256-GCM.
+// The key comes from a passphrase, or, without one, from the machine ID and
+// user, which keeps the token unreadable if the file is copied elsewhere
+// (backups, synced home directories) without requiring a prompt.
+type EncryptedFileStore struct {
+	path       string
+	passphrase string
+}
+
+// NewEncryptedFileStore returns an EncryptedFileStore at path. An empty
+// passphrase selects the machine key.
+func NewEncryptedFileStore(path, passphrase string) *EncryptedFileStore {
+	return &EncryptedFileStore{path: path, passphrase: passphrase}
+}
+
+// sealedToken is the on-disk form of an encrypted token.
+type sealedToken struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"` // "pbkdf2-sha256" or "machine"
+	Salt    []byte `json:"salt"`
+	Iter    int    `json:"iter,omitempty"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// Load implements TokenStore.
+func (s *EncryptedFileStore) Load() (*Token, error) {
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sealed sealedToken
+	if err := json.Unmarshal(raw, &sealed); err != nil || sealed.Version != 1 {
+		return nil, fmt.Errorf("%s is not an encrypted token file", s.path)
+	}
+	key, err := s.key(sealed.KDF, sealed.Salt, sealed.Iter)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := aead.Open(nil, sealed.Nonce, sealed.Data, []byte(sealed.KDF))
+	if err != nil {
+		if sealed.KDF == "machine" {
+			return nil, fmt.Errorf("decrypting %s: the token was encrypted on another machine or for another user", s.path)
+		}
+		return nil, fmt.Errorf("decrypting %s: wrong passphrase", s.path)
+	}
+	return decodeToken(data)
+}
+
+// Save implements TokenStore. Each save uses a fresh salt and nonce.
+func (s *EncryptedFileStore) Save(t *Token) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	sealed := sealedToken{Version: 1, KDF: "machine", Salt: make([]byte, 16)}
+	if s.passphrase != "" {
+		sealed.KDF, sealed.Iter = "pbkdf2-sha256", pbkdf2Iterations
+	}
+	if _, err := rand.Read(sealed.Salt); err != nil {
+		return err
+	}
+	key, err := s.key(sealed.KDF, sealed.Salt, sealed.Iter)
+	if err != nil {
+		return err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	sealed.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(sealed.Nonce); err != nil {
+		return err
+	}
+	sealed.Data = aead.Seal(nil, sealed.Nonce, data, []byte(sealed.KDF))
+
+	out, err := json.MarshalIndent(sealed, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, out)
+}
+
+// key derives the AES key for the given KDF and parameters.
+func (s *EncryptedFileStore) key(kdf string, salt []byte, iter int) ([]byte, error) {
+	switch kdf {
+	case "pbkdf2-sha256":
+		if s.passphrase == "" {
+			return nil, fmt.Errorf("%s is passphrase-encrypted; set SPOTIFY_TOKEN_PASSPHRASE", s.path)
+		}
+		return pbkdf2.Key(sha256.New, s.passphrase, salt, iter, 32)
+	case "machine":
+		secret, err := machineSecret()
+		if err != nil {
+			return nil, err
+		}
+		return hkdf.Key(sha256.New, secret, salt, "spotify-cli webapi token", 32)
+	}
+	return nil, fmt.Errorf("%s: unknown key derivation %q", s.path, kdf)
+}
+
+// machineSecret identifies this machine and user. It is not secret from
+// other processes of the same user, only from copies of the file taken
+// elsewhere.
+func machineSecret() ([]byte, error) {
+	var id []byte
+	for _, p := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if b, err := os.ReadFile(p); err == nil && len(strings.TrimSpace(string(b))) > 0 {
+			id = []byte(strings.TrimSpace(string(b)))
+			break
+		}
+	}
+	if id == nil {
+		return nil, errors.New("no machine ID found for the machine key; set SPOTIFY_TOKEN_PASSPHRASE instead")
+	}
+	u, err := user.Current()
+	if err != nil {
+		return nil, err
+	}
+	return append(id, []byte("\x00"+u.Uid)...), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ReadOnlyStore supplies a token from outside the user's home directory, such
+// as an environment variable or an inherited file descriptor in CI and
+// containers. Refreshed tokens are kept in memory only; Save never writes.
+type ReadOnlyStore struct {
+	name string
+	read func() ([]byte, error)
+
+	once sync.Once
+	data []byte
+	err  error
+	tok  *Token // latest token saved during this run
+	mu   sync.Mutex
+}
+
+// NewEnvStore reads the token from the environment variable name. The value
+// is either a token JSON document or a bare refresh token.
+func NewEnvStore(name string) *ReadOnlyStore {
+	return &ReadOnlyStore{name: "$" + name, read: func() ([]byte, error) {
+		return []byte(os.Getenv(name)), nil
+	}}
+}
+
+// NewFDStore reads the token, in the same formats as NewEnvStore, from an
+// inherited file descriptor, e.g. one set up with a shell redirection such as
+// 3<token.json. The descriptor is read once.
+func NewFDStore(fd uintptr) *ReadOnlyStore {
+	name := fmt.Sprintf("fd %d", fd)
+	return &ReadOnlyStore{name: name, read: func() ([]byte, error) {
+		f := os.NewFile(fd, name)
+		if f == nil {
+			return nil, fmt.Errorf("%s is not open", name)
+		}
+		defer f.Close()
+		return io.ReadAll(f)
+	}}
+}
+
+// Load implements TokenStore.
+func (s *ReadOnlyStore) Load() (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tok != nil {
+		return s.tok, nil
+	}
+	s.once.Do(func() { s.data, s.err = s.read() })
+	if s.err != nil {
+		return nil, fmt.Errorf("reading token from %s: %w", s.name, s.err)
+	}
+	data := strings.TrimSpace(string(s.data))
+	if data == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(data, "{") {
+		// A bare refresh token: the access token is fetched on first use.
+		// Its scopes are unknown, so assume it was granted what we request.
+		return &Token{RefreshToken: data, Scope: scopes}, nil
+	}
+	return decodeToken([]byte(data))
+}
+
+// Save implements TokenStore by remembering t for the rest of the run.
+func (s *ReadOnlyStore) Save(t *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tok = t
+	return nil
+}
+
+func decodeToken(data []byte) (*Token, error) {
+	var t Token
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("decoding token: %w", err)
+	}
+	return &t, nil
+}
+
+// writeFileAtomic replaces path with data via a synced temporary file and a
+// rename, so a crash leaves either the old or the new file, never a torn one.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,240 @@
+package webapi
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testToken() *Token {
+	return &Token{
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		Expiry:       time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		Scope:        scopes,
+	}
+}
+
+func sameToken(a, b *Token) bool {
+	return a != nil && b != nil && a.AccessToken == b.AccessToken && a.RefreshToken == b.RefreshToken &&
+		a.Expiry.Equal(b.Expiry) && a.Scope == b.Scope
+}
+
+// machineKeyAvailable skips t where the machine key cannot be derived.
+func machineKeyAvailable(t *testing.T) {
+	t.Helper()
+	if _, err := machineSecret(); err != nil {
+		t.Skip(err)
+	}
+}
+
+func TestEncryptedFileStoreRoundTrip(t *testing.T) {
+	for _, passphrase := range []string{"correct horse", ""} {
+		if passphrase == "" {
+			machineKeyAvailable(t)
+		}
+		path := filepath.Join(t.TempDir(), "token.enc")
+		s := NewEncryptedFileStore(path, passphrase)
+		if tok, err := s.Load(); tok != nil || err != nil {
+			t.Fatalf("Load before any save = %+v, %v; want nothing", tok, err)
+		}
+		want := testToken()
+		if err := s.Save(want); err != nil {
+			t.Fatal(err)
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(raw), want.RefreshToken) {
+			t.Errorf("passphrase %q: the refresh token is stored in the clear", passphrase)
+		}
+		got, err := NewEncryptedFileStore(path, passphrase).Load()
+		if err != nil {
+			t.Fatalf("passphrase %q: %v", passphrase, err)
+		}
+		if !sameToken(got, want) {
+			t.Errorf("passphrase %q: loaded %+v, want %+v", passphrase, got, want)
+		}
+	}
+}
+
+func TestEncryptedFileStoreWrongKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.enc")
+	if err := NewEncryptedFileStore(path, "correct horse").Save(testToken()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewEncryptedFileStore(path, "battery staple").Load(); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("Load with the wrong passphrase: %v", err)
+	}
+	if _, err := NewEncryptedFileStore(path, "").Load(); err == nil || !strings.Contains(err.Error(), "SPOTIFY_TOKEN_PASSPHRASE") {
+		t.Errorf("Load without the passphrase: %v", err)
+	}
+
+	// A machine key is derived with the file's salt, so another salt stands
+	// in for another machine.
+	machineKeyAvailable(t)
+	s := NewEncryptedFileStore(path, "")
+	if err := s.Save(testToken()); err != nil {
+		t.Fatal(err)
+	}
+	editSealed(t, path, func(sealed *sealedToken) { sealed.Salt[0] ^= 1 })
+	if _, err := s.Load(); err == nil || !strings.Contains(err.Error(), "another machine") {
+		t.Errorf("Load with another machine key: %v", err)
+	}
+}
+
+func TestEncryptedFileStoreTampered(t *testing.T) {
+	for name, tamper := range map[string]func(*sealedToken){
+		"ciphertext": func(s *sealedToken) { s.Data[len(s.Data)/2] ^= 1 },
+		"nonce":      func(s *sealedToken) { s.Nonce[0] ^= 1 },
+		"truncated":  func(s *sealedToken) { s.Data = s.Data[:len(s.Data)-1] },
+		"iterations": func(s *sealedToken) { s.Iter-- },
+	} {
+		path := filepath.Join(t.TempDir(), "token.enc")
+		s := NewEncryptedFileStore(path, "correct horse")
+		if err := s.Save(testToken()); err != nil {
+			t.Fatal(err)
+		}
+		editSealed(t, path, tamper)
+		if tok, err := s.Load(); err == nil {
+			t.Errorf("%s: tampered file loaded as %+v", name, tok)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "token.enc")
+	if err := os.WriteFile(path, []byte(`{"access_token": "plain"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewEncryptedFileStore(path, "correct horse").Load(); err == nil {
+		t.Error("a plain-text token loaded from the encrypted store")
+	}
+}
+
+func editSealed(t *testing.T, path string, edit func(*sealedToken)) {
+	t.Helper()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sealed sealedToken
+	if err := json.Unmarshal(raw, &sealed); err != nil {
+		t.Fatal(err)
+	}
+	edit(&sealed)
+	if raw, err = json.Marshal(sealed); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, raw, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateToken(t *testing.T) {
+	dir := t.TempDir()
+	plainPath := filepath.Join(dir, "token.json")
+	plain := NewFileStore(plainPath)
+	enc := NewEncryptedFileStore(filepath.Join(dir, "token.enc"), "correct horse")
+
+	// Nothing to migrate.
+	if err := MigrateToken(plain, enc); err != nil {
+		t.Fatal(err)
+	}
+	if tok, _ := enc.Load(); tok != nil {
+		t.Errorf("migrating no token saved %+v", tok)
+	}
+
+	want := testToken()
+	if err := plain.Save(want); err != nil {
+		t.Fatal(err)
+	}
+	if err := MigrateToken(plain, enc); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := enc.Load(); err != nil || !sameToken(got, want) {
+		t.Errorf("migrated token = %+v, %v; want %+v", got, err, want)
+	}
+	if _, err := os.Stat(plainPath); !os.IsNotExist(err) {
+		t.Errorf("the plain-text token is still on disk: %v", err)
+	}
+
+	// A stale plain-text file does not replace the encrypted token.
+	stale := testToken()
+	stale.AccessToken = "stale"
+	if err := plain.Save(stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := MigrateToken(plain, enc); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := enc.Load(); !sameToken(got, want) {
+		t.Errorf("encrypted token = %+v after a second migration, want it kept", got)
+	}
+	if _, err := os.Stat(plainPath); !os.IsNotExist(err) {
+		t.Errorf("the stale plain-text token is still on disk: %v", err)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+	path := filepath.Join(dir, "token.json")
+	for _, data := range []string{"first", "second"} {
+		if err := writeFileAtomic(path, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil || string(got) != data {
+			t.Errorf("file = %q, %v; want %q", got, err, data)
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("mode = %v, want 0600", mode)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("%d files in the directory, want only the token", len(entries))
+	}
+}
+
+func TestEnvStore(t *testing.T) {
+	const name = "SPOTIFY_TEST_WEBAPI_TOKEN"
+
+	t.Setenv(name, "  AQBrefresh-token \n")
+	s := NewEnvStore(name)
+	tok, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.RefreshToken != "AQBrefresh-token" || tok.AccessToken != "" || !tok.coversScopes() {
+		t.Errorf("bare refresh token loaded as %+v", tok)
+	}
+	// Refreshed tokens stay in memory; the variable is untouched.
+	fresh := testToken()
+	if err := s.Save(fresh); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Load(); !sameToken(got, fresh) {
+		t.Errorf("Load after Save = %+v, want %+v", got, fresh)
+	}
+	if v := os.Getenv(name); v != "  AQBrefresh-token \n" {
+		t.Errorf("$%s = %q after Save", name, v)
+	}
+
+	want := testToken()
+	data, _ := json.Marshal(want)
+	t.Setenv(name, string(data))
+	if got, err := NewEnvStore(name).Load(); err != nil || !sameToken(got, want) {
+		t.Errorf("JSON token loaded as %+v, %v; want %+v", got, err, want)
+	}
+
+	t.Setenv(name, "")
+	if got, err := NewEnvStore(name).Load(); got != nil || err != nil {
+		t.Errorf("empty variable loaded as %+v, %v; want nothing", got, err)
+	}
+}
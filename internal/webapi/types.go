@@ -3,6 +3,8 @@
 // go-librespot daemon, not by this package.
 package webapi
 
+import "strings"
+
 // User is a subset of the current user's profile (GET /me).
 type User struct {
 	ID          string `json:"id"`
@@ -10,17 +12,82 @@ type User struct {
 	Product     string `json:"product"` // "premium", "free", ...
 }
 
-// Track is a subset of a Spotify track object.
+// Track is a subset of a Spotify track object. Lists that mix in podcast
+// episodes (playlists, the queue) decode them as a Track too, with Show set
+// instead of Artists and Album.
+//
+// Tracks fetched for the user's market may be relinked: URI is then the
+// version playable in that market and LinkedFrom the one the user saved or
+// added. IsPlayable and Restrictions are only present for such requests.
 type Track struct {
-	URI      string   `json:"uri"`
-	Name     string   `json:"name"`
-	Duration int      `json:"duration_ms"`
-	Artists  []Artist `json:"artists"`
-	Album    Album    `json:"album"`
+	URI          string        `json:"uri"`
+	Name         string        `json:"name"`
+	Duration     int           `json:"duration_ms"`
+	Artists      []Artist      `json:"artists"`
+	Album        Album         `json:"album"`
+	Show         *Show         `json:"show"` // episodes only
+	Explicit     bool          `json:"explicit"`
+	IsPlayable   *bool         `json:"is_playable,omitempty"`
+	Restrictions *Restrictions `json:"restrictions,omitempty"`
+	LinkedFrom   *LinkedTrack  `json:"linked_from,omitempty"`
+}
+
+// Restrictions explains why content is not playable.
+type Restrictions struct {
+	Reason string `json:"reason"` // "market", "product" or "explicit"
+}
+
+// LinkedTrack is the originally requested track of a relinked one.
+type LinkedTrack struct {
+	ID  string `json:"id"`
+	URI string `json:"uri"`
+}
+
+// Playable reports whether t can be played in the user's market. Tracks
+// fetched without a market carry no playability and count as playable.
+func (t Track) Playable() bool {
+	if t.IsPlayable != nil {
+		return *t.IsPlayable
+	}
+	return t.Restrictions == nil
+}
+
+// UnplayableReason describes why t is not playable, for display.
+func (t Track) UnplayableReason() string {
+	if t.Restrictions != nil {
+		switch t.Restrictions.Reason {
+		case "market":
+			return "not available in your country"
+		case "product":
+			return "not available with your subscription"
+		case "explicit":
+			return "explicit content is turned off"
+		}
+	}
+	return "not available"
+}
+
+// LibraryURI is the URI the user's library and playlists know t by: the
+// original track's when t was relinked, URI otherwise. Saving, liking and
+// playlist edits use it; playback uses URI.
+func (t Track) LibraryURI() string {
+	if t.LinkedFrom != nil && t.LinkedFrom.URI != "" {
+		return t.LinkedFrom.URI
+	}
+	return t.URI
+}
+
+// IsEpisode reports whether t is a podcast episode.
+func (t Track) IsEpisode() bool {
+	return strings.HasPrefix(t.URI, "spotify:episode:")
 }
 
-// ArtistNames joins the track's artist names with ", ".
+// ArtistNames joins the track's artist names with ", ". For an episode it
+// returns the show name.
 func (t Track) ArtistNames() string {
+	if len(t.Artists) == 0 && t.Show != nil {
+		return t.Show.Name
+	}
 	names := make([]string, 0, len(t.Artists))
 	for _, a := range t.Artists {
 		names = append(names, a.Name)
@@ -28,10 +95,17 @@ func (t Track) ArtistNames() string {
 	return joinComma(names)
 }
 
-// Artist is a subset of a Spotify artist object.
+// Artist is a subset of a Spotify artist object. Genres, Followers and
+// Popularity are only populated by the full object (GET /artists/{id}).
 type Artist struct {
-	URI  string `json:"uri"`
-	Name string `json:"name"`
+	ID         string   `json:"id"`
+	URI        string   `json:"uri"`
+	Name       string   `json:"name"`
+	Genres     []string `json:"genres"`
+	Popularity int      `json:"popularity"`
+	Followers  struct {
+		Total int `json:"total"`
+	} `json:"followers"`
 }
 
 // Album is a subset of a Spotify album object.
@@ -41,6 +115,9 @@ type Album struct {
 	Name        string   `json:"name"`
 	Artists     []Artist `json:"artists"`
 	TotalTracks int      `json:"total_tracks"`
+	ReleaseDate string   `json:"release_date"`
+	AlbumType   string   `json:"album_type"`  // "album", "single", "compilation"
+	AlbumGroup  string   `json:"album_group"` // artist albums only; adds "appears_on"
 }
 
 // ArtistNames joins the album's artist names with ", ".
@@ -54,16 +131,68 @@ func (a Album) ArtistNames() string {
 
 // Playlist is a subset of a Spotify playlist object.
 type Playlist struct {
-	URI    string `json:"uri"`
-	Name   string `json:"name"`
-	Owner  Owner  `json:"owner"`
-	Tracks struct {
+	ID            string `json:"id"`
+	URI           string `json:"uri"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Public        bool   `json:"public"`
+	Collaborative bool   `json:"collaborative"`
+	SnapshotID    string `json:"snapshot_id"`
+	Owner         Owner  `json:"owner"`
+	Tracks        struct {
 		Total int `json:"total"`
 	} `json:"tracks"`
 }
 
+// Show is a subset of a Spotify show (podcast) object.
+type Show struct {
+	ID            string `json:"id"`
+	URI           string `json:"uri"`
+	Name          string `json:"name"`
+	Publisher     string `json:"publisher"`
+	TotalEpisodes int    `json:"total_episodes"`
+}
+
+// Episode is a subset of a Spotify podcast episode object. Show is only set
+// on the full object (GET /episodes/{id}), not in a show's episode list.
+type Episode struct {
+	ID          string       `json:"id"`
+	URI         string       `json:"uri"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Duration    int          `json:"duration_ms"`
+	ReleaseDate string       `json:"release_date"`
+	ResumePoint *ResumePoint `json:"resume_point"` // nil without user-read-playback-position
+	Show        *Show        `json:"show"`
+
+	IsPlayable   *bool         `json:"is_playable,omitempty"`
+	Restrictions *Restrictions `json:"restrictions,omitempty"`
+}
+
+// ResumePoint is the user's listening progress in an episode.
+type ResumePoint struct {
+	FullyPlayed      bool `json:"fully_played"`
+	ResumePositionMs int  `json:"resume_position_ms"`
+}
+
+// Resume returns where playback of the episode should continue, in ms: the
+// saved position, or 0 for unstarted and finished episodes.
+func (e Episode) Resume() int {
+	if e.ResumePoint == nil || e.ResumePoint.FullyPlayed {
+		return 0
+	}
+	return e.ResumePoint.ResumePositionMs
+}
+
+// Track returns the episode in the shape of a Track, for lists and the queue.
+func (e Episode) Track() Track {
+	return Track{URI: e.URI, Name: e.Name, Duration: e.Duration, Show: e.Show,
+		IsPlayable: e.IsPlayable, Restrictions: e.Restrictions}
+}
+
 // Owner is the owner of a playlist.
 type Owner struct {
+	ID          string `json:"id"`
 	DisplayName string `json:"display_name"`
 }
 
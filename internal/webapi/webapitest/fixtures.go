@@ -0,0 +1,135 @@
+package webapitest
+
+import (
+	"strconv"
+	"strings"
+)
+
+// User is the fixture profile served by GET /me.
+type User struct {
+	ID          string
+	DisplayName string
+	Product     string
+}
+
+func (u User) json() map[string]any {
+	return map[string]any{
+		"id":           u.ID,
+		"uri":          "spotify:user:" + u.ID,
+		"display_name": u.DisplayName,
+		"product":      u.Product,
+	}
+}
+
+// Track is a fixture track. URI defaults to spotify:track:{ID}; set it to
+// model other playlist items such as episodes or local files.
+//
+// Restriction ("market", "product", "explicit") makes the track unplayable,
+// and LinkedFrom, an original track ID, makes it a relinked replacement, as
+// Spotify reports them for requests with a market.
+type Track struct {
+	ID          string
+	URI         string
+	Name        string
+	Artist      string
+	DurationMs  int
+	Restriction string
+	LinkedFrom  string
+}
+
+// NewTrack returns a three-minute track by a single artist.
+func NewTrack(id, name, artist string) Track {
+	return Track{ID: id, Name: name, Artist: artist, DurationMs: 180000}
+}
+
+func (t Track) uri() string {
+	if t.URI != "" {
+		return t.URI
+	}
+	return "spotify:track:" + t.ID
+}
+
+// json renders the track object, embedding album when given.
+func (t Track) json(album map[string]any) map[string]any {
+	obj := map[string]any{
+		"id":          t.ID,
+		"uri":         t.uri(),
+		"name":        t.Name,
+		"duration_ms": t.DurationMs,
+		"artists":     []any{artistJSON(t.Artist)},
+	}
+	if album != nil {
+		obj["album"] = album
+	}
+	if t.Restriction != "" {
+		obj["is_playable"] = false
+		obj["restrictions"] = map[string]any{"reason": t.Restriction}
+	}
+	if t.LinkedFrom != "" {
+		obj["linked_from"] = map[string]any{"id": t.LinkedFrom, "uri": "spotify:track:" + t.LinkedFrom}
+	}
+	return obj
+}
+
+// Album is a fixture album with its tracks.
+type Album struct {
+	ID     string
+	Name   string
+	Artist string
+	Tracks []Track
+}
+
+func (a Album) json() map[string]any {
+	return map[string]any{
+		"id":           a.ID,
+		"uri":          "spotify:album:" + a.ID,
+		"name":         a.Name,
+		"album_type":   "album",
+		"total_tracks": len(a.Tracks),
+		"release_date": "2020-01-01",
+		"artists":      []any{artistJSON(a.Artist)},
+	}
+}
+
+// Playlist is a fixture playlist. Forbidden makes its items endpoint return
+// 403 unless the fixture user owns it, as development-mode apps see for other
+// users' playlists. Edits through the server bump Version, which changes the
+// snapshot ID.
+type Playlist struct {
+	ID        string
+	Name      string
+	Owner     string
+	Forbidden bool
+	Tracks    []Track
+	Version   int
+}
+
+func (p Playlist) snapshot() string {
+	if p.Version == 0 {
+		return "snapshot-" + p.ID
+	}
+	return "snapshot-" + p.ID + "-" + strconv.Itoa(p.Version)
+}
+
+func (p Playlist) json() map[string]any {
+	return map[string]any{
+		"id":            p.ID,
+		"uri":           "spotify:playlist:" + p.ID,
+		"name":          p.Name,
+		"description":   "",
+		"public":        false,
+		"collaborative": false,
+		"snapshot_id":   p.snapshot(),
+		"owner":         map[string]any{"id": p.Owner, "display_name": p.Owner},
+		"tracks":        map[string]any{"total": len(p.Tracks)},
+	}
+}
+
+func artistJSON(name string) map[string]any {
+	id := strings.ToLower(strings.ReplaceAll(name, " ", ""))
+	return map[string]any{
+		"id":   id,
+		"uri":  "spotify:artist:" + id,
+		"name": name,
+	}
+}
@@ -0,0 +1,670 @@
+// Package webapitest provides a fake Spotify Web API and accounts service for
+// tests. It serves the OAuth token endpoint (PKCE code exchange and refresh)
+// and the library, playlist and search endpoints from in-memory fixtures, with
+// Spotify's paging envelopes, bearer-token checks and injectable failures.
+//
+// The package deliberately does not import webapi, so tests inside that
+// package can use it too:
+//
+//	srv := webapitest.New()
+//	defer srv.Close()
+//	auth.UseEndpoints(webapi.Endpoints{API: srv.APIBase(), Authorize: srv.AuthorizeURL(), Token: srv.TokenURL()})
+package webapitest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClientID is the only client ID the fake token endpoint accepts.
+const ClientID = "webapitest-client"
+
+// Server is a running fake. Fixture fields may be modified between requests;
+// the server locks itself while handling one.
+type Server struct {
+	*httptest.Server
+
+	mu sync.Mutex
+
+	// Fixtures.
+	User      User
+	Liked     []Track
+	Albums    []Album
+	Playlists []Playlist
+
+	// LegacyPlaylistItems serves playlist items with only the pre-2026
+	// "track" field instead of both "item" and "track".
+	LegacyPlaylistItems bool
+	// TokenLifetime is the expires_in given to issued access tokens.
+	TokenLifetime time.Duration
+
+	access   map[string]time.Time // access token -> expiry
+	refresh  map[string]string    // valid refresh token -> granted scope
+	codes    map[string]grant     // unredeemed authorization codes
+	failures map[string][]Failure // path -> queued failures
+	requests []string
+	seq      int
+}
+
+// grant is what an authorization code was issued for.
+type grant struct {
+	challenge string // PKCE S256 challenge
+	scope     string
+}
+
+// Failure is a canned error response for one request.
+type Failure struct {
+	Status     int
+	RetryAfter time.Duration // sent as Retry-After when non-zero
+}
+
+// New starts a fake with a small default library: three liked tracks, two
+// albums and two playlists, one of them owned by the fixture user.
+func New() *Server {
+	s := &Server{
+		User:          User{ID: "tester", DisplayName: "Test User", Product: "premium"},
+		TokenLifetime: time.Hour,
+		access:        make(map[string]time.Time),
+		refresh:       make(map[string]string),
+		codes:         make(map[string]grant),
+		failures:      make(map[string][]Failure),
+	}
+	s.Liked = []Track{
+		NewTrack("liked1", "Morning Song", "Artist One"),
+		NewTrack("liked2", "Noon Song", "Artist Two"),
+		NewTrack("liked3", "Evening Song", "Artist One"),
+	}
+	s.Albums = []Album{
+		{ID: "album1", Name: "First Album", Artist: "Artist One", Tracks: []Track{
+			NewTrack("a1t1", "Opening", "Artist One"),
+			NewTrack("a1t2", "Closing", "Artist One"),
+		}},
+		{ID: "album2", Name: "Second Album", Artist: "Artist Two", Tracks: []Track{
+			NewTrack("a2t1", "Only Track", "Artist Two"),
+		}},
+	}
+	s.Playlists = []Playlist{
+		{ID: "pl1", Name: "Mine", Owner: "tester", Tracks: []Track{
+			NewTrack("p1t1", "Road Trip", "Artist Three"),
+			{ID: "ep1", Name: "An Episode", URI: "spotify:episode:ep1"},
+			NewTrack("p1t2", "Night Drive", "Artist Three"),
+		}},
+		{ID: "pl2", Name: "Someone Else's", Owner: "other", Tracks: []Track{
+			NewTrack("p2t1", "Borrowed", "Artist Four"),
+		}},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorize", s.handleAuthorize)
+	mux.HandleFunc("/api/token", s.handleToken)
+	mux.HandleFunc("/v1/", s.handleAPI)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// APIBase is the Web API base URL to use in place of Spotify's.
+func (s *Server) APIBase() string { return s.URL + "/v1" }
+
+// AuthorizeURL is the OAuth authorize URL to use in place of Spotify's.
+func (s *Server) AuthorizeURL() string { return s.URL + "/authorize" }
+
+// TokenURL is the OAuth token URL to use in place of Spotify's.
+func (s *Server) TokenURL() string { return s.URL + "/api/token" }
+
+// IssueToken mints a valid access/refresh token pair with the given lifetime
+// and granted scope, for tests that start from a saved login.
+func (s *Server) IssueToken(lifetime time.Duration, scope string) (access, refresh string, expiry time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	access, refresh = s.newID("access"), s.newID("refresh")
+	expiry = time.Now().Add(lifetime)
+	s.access[access] = expiry
+	s.refresh[refresh] = scope
+	return access, refresh, expiry
+}
+
+// ExpireAccessTokens makes every issued access token invalid, so the next API
+// request gets a 401 regardless of the expiry the client believes.
+func (s *Server) ExpireAccessTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.access)
+}
+
+// RevokeRefreshTokens makes every issued refresh token invalid.
+func (s *Server) RevokeRefreshTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.refresh)
+}
+
+// Fail queues failures for path (relative to the API base, without query,
+// e.g. "/me/tracks"). Each request to path consumes one; later requests
+// succeed again.
+func (s *Server) Fail(path string, f ...Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[path] = append(s.failures[path], f...)
+}
+
+// Requests returns the method and path ("GET /me/tracks") of every request
+// received so far, token requests included, oldest first.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Authorize simulates the user approving the app in a browser: it returns the
+// authorization code the redirect would carry for the given PKCE challenge
+// and requested scope.
+func (s *Server) Authorize(challenge, scope string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code := s.newID("code")
+	s.codes[code] = grant{challenge: challenge, scope: scope}
+	return code
+}
+
+func (s *Server) newID(kind string) string {
+	s.seq++
+	return kind + "-" + strconv.Itoa(s.seq)
+}
+
+// handleAuthorize redirects straight back to redirect_uri with a code, as if
+// the user had approved the request.
+func (s *Server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	s.log(r)
+	if q.Get("client_id") != ClientID {
+		http.Error(w, "INVALID_CLIENT: Invalid client", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "code_challenge required", http.StatusBadRequest)
+		return
+	}
+	code := s.Authorize(q.Get("code_challenge"), q.Get("scope"))
+	to := q.Get("redirect_uri") + "?" + url.Values{"code": {code}, "state": {q.Get("state")}}.Encode()
+	http.Redirect(w, r, to, http.StatusFound)
+}
+
+// handleToken implements the authorization_code (with PKCE verification) and
+// refresh_token grants. Errors use the OAuth error body Spotify returns.
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	s.log(r)
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		oauthError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if r.PostForm.Get("client_id") != ClientID {
+		oauthError(w, http.StatusBadRequest, "invalid_client", "Invalid client")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var refresh, scope string
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		code := r.PostForm.Get("code")
+		g, ok := s.codes[code]
+		if !ok {
+			oauthError(w, http.StatusBadRequest, "invalid_grant", "Invalid authorization code")
+			return
+		}
+		delete(s.codes, code)
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+			oauthError(w, http.StatusBadRequest, "invalid_grant", "code_verifier was incorrect")
+			return
+		}
+		refresh, scope = s.newID("refresh"), g.scope
+		s.refresh[refresh] = scope
+	case "refresh_token":
+		var ok bool
+		if scope, ok = s.refresh[r.PostForm.Get("refresh_token")]; !ok {
+			oauthError(w, http.StatusBadRequest, "invalid_grant", "Refresh token revoked")
+			return
+		}
+		// Like Spotify, a refresh usually keeps the refresh token and omits it.
+	default:
+		oauthError(w, http.StatusBadRequest, "unsupported_grant_type", "grant_type must be authorization_code or refresh_token")
+		return
+	}
+
+	access := s.newID("access")
+	s.access[access] = time.Now().Add(s.TokenLifetime)
+	resp := map[string]any{
+		"access_token": access,
+		"token_type":   "Bearer",
+		"expires_in":   int(s.TokenLifetime / time.Second),
+		"scope":        scope,
+	}
+	if refresh != "" {
+		resp["refresh_token"] = refresh
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func oauthError(w http.ResponseWriter, status int, code, desc string) {
+	writeJSON(w, status, map[string]string{"error": code, "error_description": desc})
+}
+
+// handleAPI checks the bearer token and any queued failure, then routes to
+// the fixture handlers.
+func (s *Server) handleAPI(w http.ResponseWriter, r *http.Request) {
+	s.log(r)
+	path := strings.TrimPrefix(r.URL.Path, "/v1")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if exp, ok := s.access[token]; !ok || time.Now().After(exp) {
+		apiError(w, http.StatusUnauthorized, "The access token expired")
+		return
+	}
+	if queue := s.failures[path]; len(queue) > 0 {
+		f := queue[0]
+		s.failures[path] = queue[1:]
+		if f.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(f.RetryAfter.Round(time.Second)/time.Second)))
+		}
+		apiError(w, f.Status, http.StatusText(f.Status))
+		return
+	}
+
+	switch {
+	case path == "/me":
+		writeJSON(w, http.StatusOK, s.User.json())
+	case path == "/me/tracks":
+		s.serveLiked(w, r)
+	case path == "/me/tracks/contains":
+		ids := strings.Split(r.URL.Query().Get("ids"), ",")
+		out := make([]bool, len(ids))
+		for i, id := range ids {
+			out[i] = s.likedIndex(id) >= 0
+		}
+		writeJSON(w, http.StatusOK, out)
+	case path == "/me/albums":
+		items := make([]any, len(s.Albums))
+		for i, a := range s.Albums {
+			items[i] = map[string]any{"added_at": "2024-01-01T00:00:00Z", "album": a.json()}
+		}
+		s.writePage(w, r, items)
+	case path == "/me/playlists" && r.Method == http.MethodPost:
+		var body struct {
+			Name string `json:"name"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		p := Playlist{ID: s.newID("playlist"), Name: body.Name, Owner: s.User.ID}
+		s.Playlists = append(s.Playlists, p)
+		writeJSON(w, http.StatusCreated, p.json())
+	case path == "/me/playlists":
+		items := make([]any, len(s.Playlists))
+		for i, p := range s.Playlists {
+			items[i] = p.json()
+		}
+		s.writePage(w, r, items)
+	case strings.HasPrefix(path, "/albums/") && strings.HasSuffix(path, "/tracks"):
+		id := strings.TrimSuffix(strings.TrimPrefix(path, "/albums/"), "/tracks")
+		for _, a := range s.Albums {
+			if a.ID == id {
+				items := make([]any, len(a.Tracks))
+				for i, t := range a.Tracks {
+					items[i] = t.json(nil)
+				}
+				s.writePage(w, r, items)
+				return
+			}
+		}
+		apiError(w, http.StatusNotFound, "Non existing id")
+	case path == "/tracks":
+		// Batch lookup: unknown IDs come back as null, in request order.
+		var out []any
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			var obj any
+			for _, t := range s.allTracks() {
+				if t.ID == id {
+					obj = t.json(nil)
+					break
+				}
+			}
+			out = append(out, obj)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"tracks": out})
+	case path == "/episodes":
+		// Like /tracks, for fixture items with an episode URI.
+		var out []any
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			var obj any
+			for _, t := range s.allTracks() {
+				if t.ID == id && strings.HasPrefix(t.uri(), "spotify:episode:") {
+					obj = map[string]any{"id": t.ID, "uri": t.uri(), "name": t.Name, "duration_ms": t.DurationMs}
+					break
+				}
+			}
+			out = append(out, obj)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"episodes": out})
+	case strings.HasPrefix(path, "/playlists/") && strings.HasSuffix(path, "/items"):
+		s.servePlaylistItems(w, r, strings.TrimSuffix(strings.TrimPrefix(path, "/playlists/"), "/items"))
+	case strings.HasPrefix(path, "/playlists/") && strings.Count(path, "/") == 2:
+		id := strings.TrimPrefix(path, "/playlists/")
+		for _, p := range s.Playlists {
+			if p.ID == id {
+				writeJSON(w, http.StatusOK, p.json())
+				return
+			}
+		}
+		apiError(w, http.StatusNotFound, "Not found.")
+	case path == "/search":
+		s.serveSearch(w, r)
+	default:
+		apiError(w, http.StatusNotFound, "Service not found")
+	}
+}
+
+func (s *Server) serveLiked(w http.ResponseWriter, r *http.Request) {
+	ids := strings.Split(r.URL.Query().Get("ids"), ",")
+	switch r.Method {
+	case http.MethodGet:
+		items := make([]any, len(s.Liked))
+		for i, t := range s.Liked {
+			items[i] = map[string]any{"added_at": "2024-01-01T00:00:00Z", "track": t.json(nil)}
+		}
+		s.writePage(w, r, items)
+	case http.MethodPut:
+		for _, id := range ids {
+			if s.likedIndex(id) < 0 {
+				s.Liked = append([]Track{NewTrack(id, id, "Unknown Artist")}, s.Liked...)
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		for _, id := range ids {
+			if i := s.likedIndex(id); i >= 0 {
+				s.Liked = append(s.Liked[:i], s.Liked[i+1:]...)
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		apiError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (s *Server) likedIndex(id string) int {
+	for i, t := range s.Liked {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// servePlaylistItems lists a playlist's items (GET), inserts them (POST, at
+// "position" or the end), replaces or reorders them (PUT, with "uris" or a
+// range) and removes every occurrence of the given URIs (DELETE). Edits bump
+// the playlist's version and answer with the new snapshot ID.
+func (s *Server) servePlaylistItems(w http.ResponseWriter, r *http.Request, id string) {
+	i := s.playlistIndex(id)
+	if i < 0 {
+		apiError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+	p := &s.Playlists[i]
+	if r.Method == http.MethodGet {
+		if p.Owner != s.User.ID && p.Forbidden {
+			apiError(w, http.StatusForbidden, "Forbidden")
+			return
+		}
+		items := make([]any, len(p.Tracks))
+		for i, t := range p.Tracks {
+			obj := t.json(nil)
+			if s.LegacyPlaylistItems {
+				items[i] = map[string]any{"added_at": "2024-01-01T00:00:00Z", "track": obj}
+			} else {
+				items[i] = map[string]any{"added_at": "2024-01-01T00:00:00Z", "item": obj, "track": obj}
+			}
+		}
+		s.writePage(w, r, items)
+		return
+	}
+
+	var body struct {
+		URIs         []string `json:"uris"`
+		Position     *int     `json:"position"`
+		RangeStart   int      `json:"range_start"`
+		InsertBefore int      `json:"insert_before"`
+		RangeLength  *int     `json:"range_length"`
+		Items        []struct {
+			URI string `json:"uri"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apiError(w, http.StatusBadRequest, "Error parsing JSON.")
+		return
+	}
+	status := http.StatusOK
+	switch r.Method {
+	case http.MethodPost:
+		at := len(p.Tracks)
+		if body.Position != nil {
+			if *body.Position < 0 || *body.Position > len(p.Tracks) {
+				apiError(w, http.StatusBadRequest, "Index out of bounds")
+				return
+			}
+			at = *body.Position
+		}
+		p.Tracks = append(p.Tracks[:at], append(s.lookup(body.URIs), p.Tracks[at:]...)...)
+		status = http.StatusCreated
+	case http.MethodPut:
+		if body.URIs != nil {
+			p.Tracks = s.lookup(body.URIs)
+			break
+		}
+		n := 1
+		if body.RangeLength != nil {
+			n = *body.RangeLength
+		}
+		start, before := body.RangeStart, body.InsertBefore
+		if n < 1 || start < 0 || start+n > len(p.Tracks) || before < 0 || before > len(p.Tracks) {
+			apiError(w, http.StatusBadRequest, "Index out of bounds")
+			return
+		}
+		moved := append([]Track(nil), p.Tracks[start:start+n]...)
+		rest := append(append([]Track(nil), p.Tracks[:start]...), p.Tracks[start+n:]...)
+		if before > start {
+			before -= n
+			if before < start {
+				before = start // inside the moved range: no change
+			}
+		}
+		p.Tracks = append(rest[:before], append(moved, rest[before:]...)...)
+	case http.MethodDelete:
+		drop := make(map[string]bool, len(body.Items))
+		for _, it := range body.Items {
+			drop[it.URI] = true
+		}
+		kept := p.Tracks[:0]
+		for _, t := range p.Tracks {
+			if !drop[t.uri()] {
+				kept = append(kept, t)
+			}
+		}
+		p.Tracks = kept
+	default:
+		apiError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	p.Version++
+	writeJSON(w, status, map[string]any{"snapshot_id": p.snapshot()})
+}
+
+func (s *Server) playlistIndex(id string) int {
+	for i, p := range s.Playlists {
+		if p.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// lookup turns URIs into fixture tracks, falling back to a bare track for
+// URIs the fixtures do not know.
+func (s *Server) lookup(uris []string) []Track {
+	out := make([]Track, 0, len(uris))
+	for _, u := range uris {
+		parts := strings.Split(u, ":")
+		t := Track{ID: parts[len(parts)-1], URI: u}
+		for _, known := range s.allTracks() {
+			if known.uri() == u {
+				t = known
+				break
+			}
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// serveSearch matches the query case-insensitively against fixture names and
+// returns one paging object per requested type.
+func (s *Server) serveSearch(w http.ResponseWriter, r *http.Request) {
+	q := strings.ToLower(r.URL.Query().Get("q"))
+	if q == "" {
+		apiError(w, http.StatusBadRequest, "No search query")
+		return
+	}
+	match := func(name string) bool { return strings.Contains(strings.ToLower(name), q) }
+
+	resp := map[string]any{}
+	for _, typ := range strings.Split(r.URL.Query().Get("type"), ",") {
+		var items []any
+		switch typ {
+		case "track":
+			seen := map[string]bool{}
+			for _, t := range s.allTracks() {
+				if match(t.Name) && !seen[t.ID] {
+					seen[t.ID] = true
+					items = append(items, t.json(nil))
+				}
+			}
+		case "album":
+			for _, a := range s.Albums {
+				if match(a.Name) {
+					items = append(items, a.json())
+				}
+			}
+		case "playlist":
+			for _, p := range s.Playlists {
+				if match(p.Name) {
+					items = append(items, p.json())
+				}
+			}
+		case "artist", "show", "episode":
+			// No fixtures; an empty section like a search with no hits.
+		default:
+			apiError(w, http.StatusBadRequest, "Bad search type field "+typ)
+			return
+		}
+		resp[typ+"s"] = s.page(r, items)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) allTracks() []Track {
+	var all []Track
+	all = append(all, s.Liked...)
+	for _, a := range s.Albums {
+		all = append(all, a.Tracks...)
+	}
+	for _, p := range s.Playlists {
+		all = append(all, p.Tracks...)
+	}
+	return all
+}
+
+// writePage writes the requested window of items as a paging object.
+func (s *Server) writePage(w http.ResponseWriter, r *http.Request, items []any) {
+	writeJSON(w, http.StatusOK, s.page(r, items))
+}
+
+// page slices items by the limit and offset query parameters (defaults 20
+// and 0) and wraps them in a paging object whose "next" link points back at
+// this server.
+func (s *Server) page(r *http.Request, items []any) map[string]any {
+	q := r.URL.Query()
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	offset = max(0, min(offset, len(items)))
+	end := min(offset+limit, len(items))
+
+	var next any
+	if end < len(items) {
+		nq := url.Values{}
+		for k, v := range q {
+			nq[k] = v
+		}
+		nq.Set("offset", strconv.Itoa(end))
+		nq.Set("limit", strconv.Itoa(limit))
+		next = s.URL + r.URL.Path + "?" + nq.Encode()
+	}
+	window := items[offset:end]
+	if window == nil {
+		window = []any{}
+	}
+	return map[string]any{
+		"href":   s.URL + r.URL.RequestURI(),
+		"items":  window,
+		"limit":  limit,
+		"offset": offset,
+		"total":  len(items),
+		"next":   next,
+	}
+}
+
+func (s *Server) log(r *http.Request) {
+	path := r.URL.Path
+	if p, ok := strings.CutPrefix(path, "/v1"); ok {
+		path = p
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+path)
+	s.mu.Unlock()
+}
+
+func apiError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]any{"error": map[string]any{"status": status, "message": msg}})
+}
+
+// writeJSON encodes v before writing anything, so a value that cannot be
+// encoded fails just that request with a 500 rather than the whole test.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("webapitest: encoding response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(b, '\n'))
+}